@@ -30,7 +30,7 @@ var addCmd = &cobra.Command{
 	Long: `Once you have completed a task, use this command to add that newly
 completed task to the database with an optional note.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runAdd(cmd, args)
+		runAdd(cmd, args, openStore())
 	},
 }
 
@@ -56,12 +56,12 @@ func getFavorite(index int) Favorite {
 		os.Exit(1)
 	}
 
-	if index >= len(config.Favorites) {
+	if index >= len(config.activeFavorites()) {
 		log.Fatalf("%s: Favorite[%d] not found in configuration file[%s].\n", color.RedString(constants.FATAL_NORMAL_CASE), index, viper.ConfigFileUsed())
 		os.Exit(1)
 	}
 
-	return config.Favorites[index]
+	return config.activeFavorites()[index]
 }
 
 func init() {
@@ -73,7 +73,7 @@ func init() {
 	rootCmd.AddCommand(addCmd)
 }
 
-func runAdd(cmd *cobra.Command, args []string) {
+func runAdd(cmd *cobra.Command, args []string, store database.Store) {
 	// Get the current date/time.
 	var addTime carbon.Carbon = carbon.Now()
 
@@ -164,6 +164,5 @@ func runAdd(cmd *cobra.Command, args []string) {
 	log.Printf("%s %s.\n", color.GreenString(constants.ADDING), entry.Dump(false))
 
 	// Write the new Entry to the database.
-	db := database.New(viper.GetString(constants.DATABASE_FILE))
-	db.InsertNewEntry(entry)
+	exitOnError(store.InsertNewEntry(entry, cmd.Name()))
 }
@@ -19,7 +19,6 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"timetracker/internal/database"
 	"timetracker/internal/models"
@@ -33,7 +32,7 @@ var amendCmd = &cobra.Command{
 	Long: `Amend is a convenient way to modify an entry, default is the last
 entry.  It lets you modify the project, task, and/or datetime.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runAmend(cmd, args)
+		runAmend(cmd, args, openStore())
 	},
 }
 
@@ -42,11 +41,10 @@ func init() {
 	rootCmd.AddCommand(amendCmd)
 }
 
-func runAmend(cmd *cobra.Command, _ []string) {
+func runAmend(cmd *cobra.Command, _ []string, store database.Store) {
 	var entry models.Entry
 
 	today, _ := cmd.Flags().GetBool("today")
-	db := database.New(viper.GetString(constants.DATABASE_FILE))
 	if today {
 		var input_value string = constants.EMPTY
 
@@ -54,7 +52,9 @@ func runAmend(cmd *cobra.Command, _ []string) {
 			var t table.Writer = table.NewWriter()
 			t.SetAutoIndex(true)
 			t.AppendHeader(table.Row{"Project", "Task(s)", "Date/Time"})
-			var entries []models.Entry = db.GetEntriesForToday(carbon.Now().StartOfDay(), carbon.Now().EndOfDay())
+			entries, err := store.GetEntriesBetween(carbon.Now().StartOfDay(), carbon.Now().EndOfDay())
+			exitOnError(err)
+
 			for _, entry := range entries {
 				t.AppendRow(table.Row{entry.Project, entry.GetTasksAsString(), entry.EntryDatetime})
 			}
@@ -89,7 +89,9 @@ func runAmend(cmd *cobra.Command, _ []string) {
 		}
 	} else {
 		// Get the last Entry from the database.
-		entry = db.GetLastEntry()
+		var err error
+		entry, err = store.GetLastEntry()
+		exitOnError(err)
 	}
 
 	log.Printf("Amending...\n" + entry.Dump(true) + "\n\n")
@@ -155,7 +157,7 @@ func runAmend(cmd *cobra.Command, _ []string) {
 			e.AddEntryProperty(constants.URL, newURL)
 		}
 
-		db.UpdateEntry(e)
+		exitOnError(store.UpdateEntry(e, constants.AMEND))
 
 		log.Printf("Last entry amended.\n")
 	} else {
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/fatih/color"
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// archiveCmd represents the archive command.
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Args:  cobra.ExactArgs(0),
+	Short: "Move old entries into per-year archive databases",
+	Long: `Move the entries before the specified date, along with their properties, out
+of the database and into an archive database per year, e.g.
+'.timetracker-archive-2023.db' next to the database.  The entries are only
+removed once the archive has been verified to hold all of them.  Use
+'tt report --include-archives' to report on archived entries.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runArchive(cmd, args, openStore())
+	},
+}
+
+func init() {
+	archiveCmd.Flags().StringP(constants.BEFORE, constants.EMPTY, constants.EMPTY, "Archive the entries before this date, in "+constants.DATE_FORMAT+" format.")
+	archiveCmd.Flags().BoolP(constants.DRY_RUN, constants.EMPTY, false, "Do not actually archive anything, but show what would be archived.")
+	archiveCmd.MarkFlagRequired(constants.BEFORE)
+	rootCmd.AddCommand(archiveCmd)
+}
+
+func runArchive(cmd *cobra.Command, _ []string, store database.Store) {
+	beforeStr, _ := cmd.Flags().GetString(constants.BEFORE)
+	dryRun, _ := cmd.Flags().GetBool(constants.DRY_RUN)
+
+	var before carbon.Carbon = carbon.Parse(beforeStr)
+	if before.Error != nil {
+		log.Fatalf("%s: Invalid date[%s].  Please use the %s format.\n", color.RedString(constants.FATAL_NORMAL_CASE), beforeStr, constants.DATE_FORMAT)
+	}
+
+	before = before.StartOfDay()
+
+	// Archives are kept next to the database file, so there is nothing to
+	// archive for an in-memory store.
+	db, ok := store.(*database.Database)
+	if !ok {
+		log.Printf("There is nothing to archive in an in-memory store.\n")
+		return
+	}
+
+	counts, err := db.GetCountEntriesByYearBefore(before)
+	exitOnError(err)
+
+	if len(counts) == 0 {
+		log.Printf("There are no entries before %s to archive.\n", before.Format(constants.CARBON_DATE_FORMAT))
+		return
+	}
+
+	var years []string = make([]string, 0, len(counts))
+	for year := range counts {
+		years = append(years, year)
+	}
+	sort.Strings(years)
+
+	if dryRun {
+		for _, year := range years {
+			log.Printf("%d entries from %s would have been archived to [%s].\n", counts[year], year, archiveFilename(year))
+		}
+
+		return
+	}
+
+	backupBeforeDestructive(db, "archive")
+
+	for _, year := range years {
+		count, err := db.ArchiveYear(year, before, archiveFilename(year))
+		exitOnError(err)
+
+		log.Printf("%s %d entries from %s to [%s].\n", color.GreenString("Archived"), count, year, archiveFilename(year))
+	}
+}
+
+// The archive database filename for the specified year, e.g.
+// ".timetracker-archive-2023.db" for a database file named ".timetracker.db".
+func archiveFilename(year string) string {
+	var filename string = viper.GetString(constants.DATABASE_FILE)
+	var base string = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	return filepath.Join(filepath.Dir(filename), base+"-archive-"+year+".db")
+}
+
+// Get the archive database filenames, oldest year first.
+func archiveFilenames() []string {
+	filenames, _ := filepath.Glob(archiveFilename("[0-9][0-9][0-9][0-9]"))
+	sort.Strings(filenames)
+	return filenames
+}
+
+// Get the Entries between start and end from the store and, if requested,
+// from every archive database, ordered by date/time.
+func getEntriesBetween(store database.Store, start carbon.Carbon, end carbon.Carbon, includeArchives bool) ([]models.Entry, error) {
+	entries, err := store.GetEntriesBetween(start, end)
+	if err != nil || !includeArchives {
+		return entries, err
+	}
+
+	// Archives are kept next to the database file, so an in-memory store has
+	// none.
+	if _, ok := store.(*database.Database); !ok {
+		return entries, nil
+	}
+
+	for _, filename := range archiveFilenames() {
+		archived, err := getArchivedEntriesBetween(filename, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, archived...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return carbon.Parse(entries[i].EntryDatetime).Lt(carbon.Parse(entries[j].EntryDatetime))
+	})
+	return entries, nil
+}
+
+// Get the Entries between start and end from the archive database through a
+// read-only connection, so reporting never writes to an archive.  An archive
+// whose schema is behind is not migrated here, but by 'tt db migrate'.
+func getArchivedEntriesBetween(filename string, start carbon.Carbon, end carbon.Carbon) ([]models.Entry, error) {
+	archive, err := database.OpenReadOnly(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer archive.Close()
+
+	version, err := archive.GetSchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	if version < database.LatestSchemaVersion() {
+		return nil, fmt.Errorf("archive database[%s] is at schema version %d of %d, run 'tt db migrate' to bring it up to date", filename, version, database.LatestSchemaVersion())
+	}
+
+	return archive.GetEntriesBetween(start, end)
+}
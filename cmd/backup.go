@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/database"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// The date/time format embedded in each snapshot's filename.
+const snapshotTimeFormat string = "20060102-150405"
+
+// A snapshot is a backup copy of the database.
+type snapshot struct {
+	Path  string
+	Taken time.Time
+	Size  int64
+}
+
+// backupCmd represents the backup command.
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Args:  cobra.ExactArgs(0),
+	Short: "Take a snapshot of the database",
+	Long: `Take a consistent snapshot of the database, even while it is in use, and
+write it to the backup directory.  Afterwards, old snapshots are rotated out,
+keeping the newest snapshot of each of the last 'backup.keep_daily' days and
+of each of the last 'backup.keep_weekly' weeks.  A snapshot is also taken
+automatically before destructive commands like nuke.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runBackup(cmd, args)
+	},
+}
+
+func init() {
+	backupCmd.Flags().BoolP(constants.LIST, constants.EMPTY, false, "List the snapshots in the backup directory.")
+	rootCmd.AddCommand(backupCmd)
+}
+
+func runBackup(cmd *cobra.Command, _ []string) {
+	list, _ := cmd.Flags().GetBool(constants.LIST)
+	if list {
+		showSnapshots()
+		return
+	}
+
+	db, err := database.New(viper.GetString(constants.DATABASE_FILE))
+	exitOnError(err)
+	defer db.Close()
+
+	path, err := createSnapshot(db)
+	exitOnError(err)
+
+	log.Printf("%s database to [%s].\n", color.GreenString("Backed up"), path)
+}
+
+// If configured to do so, take a snapshot of the database before the
+// destructive command runs.  There is nothing to back up for an in-memory
+// store.
+func backupBeforeDestructive(store database.Store, command string) {
+	if !viper.GetBool(constants.BACKUP_BEFORE_DESTRUCTIVE) {
+		return
+	}
+
+	db, ok := store.(*database.Database)
+	if !ok {
+		return
+	}
+
+	path, err := createSnapshot(db)
+	exitOnError(err)
+
+	log.Printf("%s database to [%s] before %s.\n", color.GreenString("Backed up"), path, command)
+}
+
+// The prefix of every snapshot's filename, e.g. "timetracker-" for a database
+// file named ".timetracker.db".
+func snapshotPrefix() string {
+	var base string = filepath.Base(viper.GetString(constants.DATABASE_FILE))
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	return strings.TrimPrefix(base, ".") + "-"
+}
+
+// Take a snapshot of the database and then rotate out the old snapshots.
+// Returns the path of the new snapshot.
+func createSnapshot(db *database.Database) (string, error) {
+	var directory string = viper.GetString(constants.BACKUP_DIRECTORY)
+	err := os.MkdirAll(directory, 0755)
+	if err != nil {
+		return constants.EMPTY, err
+	}
+
+	var now time.Time = time.Now()
+	var path string = filepath.Join(directory, snapshotPrefix()+now.Format(snapshotTimeFormat)+".db")
+
+	// Snapshots taken within the same second would collide, so wait for the
+	// next second rather than overwrite one.
+	for {
+		_, err = os.Stat(path)
+		if os.IsNotExist(err) {
+			break
+		}
+
+		time.Sleep(time.Until(now.Truncate(time.Second).Add(time.Second)))
+		now = time.Now()
+		path = filepath.Join(directory, snapshotPrefix()+now.Format(snapshotTimeFormat)+".db")
+	}
+
+	err = db.Backup(path)
+	if err != nil {
+		return constants.EMPTY, err
+	}
+
+	snapshots, err := listSnapshots()
+	if err != nil {
+		return path, err
+	}
+
+	for _, s := range rotateSnapshots(snapshots, viper.GetInt(constants.BACKUP_KEEP_DAILY), viper.GetInt(constants.BACKUP_KEEP_WEEKLY)) {
+		err = os.Remove(s.Path)
+		if err != nil {
+			return path, err
+		}
+
+		if viper.GetBool("debug") {
+			log.Printf("Rotated out snapshot[%s].\n", s.Path)
+		}
+	}
+
+	return path, nil
+}
+
+// List the snapshots in the backup directory, newest first.
+func listSnapshots() ([]snapshot, error) {
+	var directory string = viper.GetString(constants.BACKUP_DIRECTORY)
+	files, err := os.ReadDir(directory)
+	if os.IsNotExist(err) {
+		return []snapshot{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var prefix string = snapshotPrefix()
+	var snapshots []snapshot = []snapshot{}
+	for _, f := range files {
+		var name string = f.Name()
+		if f.IsDir() || !strings.HasPrefix(name, prefix) || filepath.Ext(name) != ".db" {
+			continue
+		}
+
+		taken, err := time.ParseInLocation(snapshotTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".db"), time.Local)
+		if err != nil {
+			continue
+		}
+
+		info, err := f.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, snapshot{filepath.Join(directory, name), taken, info.Size()})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Taken.After(snapshots[j].Taken) })
+	return snapshots, nil
+}
+
+// Work out which snapshots to rotate out.  The newest snapshot of each of the
+// last keepDaily days and of each of the last keepWeekly weeks is kept; the
+// rest are returned.  When both are 0, every snapshot is kept.
+func rotateSnapshots(snapshots []snapshot, keepDaily int, keepWeekly int) []snapshot {
+	if keepDaily <= 0 && keepWeekly <= 0 {
+		return []snapshot{}
+	}
+
+	// Make sure the snapshots are newest first.
+	var sorted []snapshot = append([]snapshot{}, snapshots...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Taken.After(sorted[j].Taken) })
+
+	var days map[string]bool = make(map[string]bool)
+	var weeks map[string]bool = make(map[string]bool)
+	var removed []snapshot = []snapshot{}
+	for _, s := range sorted {
+		var keep bool = false
+
+		var day string = s.Taken.Format(constants.DATE_FORMAT)
+		if !days[day] && len(days) < keepDaily {
+			days[day] = true
+			keep = true
+		}
+
+		year, week := s.Taken.ISOWeek()
+		var yearWeek string = fmt.Sprintf("%d-W%02d", year, week)
+		if !weeks[yearWeek] && len(weeks) < keepWeekly {
+			weeks[yearWeek] = true
+			keep = true
+		}
+
+		if !keep {
+			removed = append(removed, s)
+		}
+	}
+
+	return removed
+}
+
+func showSnapshots() {
+	snapshots, err := listSnapshots()
+	exitOnError(err)
+
+	if len(snapshots) == 0 {
+		log.Printf("There are no snapshots in [%s].\n", viper.GetString(constants.BACKUP_DIRECTORY))
+		return
+	}
+
+	var t table.Writer = table.NewWriter()
+	t.AppendHeader(table.Row{"Snapshot", "Taken", "Size"})
+	for _, s := range snapshots {
+		t.AppendRow(table.Row{filepath.Base(s.Path), s.Taken.Format(time.DateTime), fmt.Sprintf("%.1f KB", float64(s.Size)/1024)})
+	}
+
+	log.Println(t.Render())
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/golang-module/carbon/v2"
 	"github.com/ijt/go-anytime"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // breakCmd represents the break command
@@ -26,7 +25,7 @@ var breakCmd = &cobra.Command{
 	Long: `If you just spent time on break, use this command to add that time
 to the database.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runBreak(cmd, args)
+		runBreak(cmd, args, openStore())
 	},
 }
 
@@ -46,7 +45,7 @@ func init() {
 	// breakCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
-func runBreak(cmd *cobra.Command, _ []string) {
+func runBreak(cmd *cobra.Command, _ []string, store database.Store) {
 	// Get the current date/time.
 	var breakTime carbon.Carbon = carbon.Now()
 
@@ -71,6 +70,5 @@ func runBreak(cmd *cobra.Command, _ []string) {
 	log.Printf("%s %s.\n", color.GreenString(constants.ADDING), entry.Dump(false))
 
 	// Write the new Entry to the database.
-	db := database.New(viper.GetString(constants.DATABASE_FILE))
-	db.InsertNewEntry(entry)
+	exitOnError(store.InsertNewEntry(entry, cmd.Name()))
 }
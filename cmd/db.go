@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"log"
+	"strings"
+	"timetracker/constants"
+	"timetracker/internal/database"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// dbCmd represents the db command.
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Database maintenance commands",
+	Long:  "Database maintenance commands.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// migrateCmd represents the db migrate command.
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Args:  cobra.ExactArgs(0),
+	Short: "Apply any pending database schema migrations",
+	Long: `Brings the database schema up to date by applying, in order, each migration
+that has not yet been applied.  Migrations are also applied automatically
+whenever the database is opened.  The archive databases, which reports only
+ever read, are brought up to date as well.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMigrate(cmd, args)
+	},
+}
+
+func init() {
+	migrateCmd.Flags().BoolP(constants.STATUS, constants.EMPTY, false, "Show the applied and pending migrations.")
+	migrateCmd.Flags().BoolP(constants.DRY_RUN, constants.EMPTY, false, "Do not actually migrate anything, but show what would be migrated.")
+	dbCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(dbCmd)
+}
+
+func runMigrate(cmd *cobra.Command, _ []string) {
+	status, _ := cmd.Flags().GetBool(constants.STATUS)
+	dryRun, _ := cmd.Flags().GetBool(constants.DRY_RUN)
+
+	// Open without migrating so we can see what is pending.
+	db, err := database.Open(viper.GetString(constants.DATABASE_FILE))
+	exitOnError(err)
+	defer db.Close()
+
+	if status {
+		showMigrationStatus(db)
+	} else if dryRun {
+		pending, err := db.GetPendingMigrations()
+		exitOnError(err)
+
+		if len(pending) == 0 {
+			showSchemaVersion(db)
+			return
+		}
+
+		for _, m := range pending {
+			log.Printf("Migration %d (%s) would have been applied:\n", m.Version, m.Description)
+			for _, statement := range m.Statements {
+				log.Printf("  %s\n", strings.TrimSpace(statement))
+			}
+		}
+	} else {
+		applied, err := db.Migrate()
+		for _, m := range applied {
+			log.Printf("%s migration %d (%s).\n", color.GreenString(constants.APPLIED), m.Version, m.Description)
+		}
+		exitOnError(err)
+
+		showSchemaVersion(db)
+
+		for _, filename := range archiveFilenames() {
+			migrateArchive(filename)
+		}
+	}
+}
+
+// Bring the archive database's schema up to date, since reports open archives
+// read-only and never migrate them.
+func migrateArchive(filename string) {
+	archive, err := database.Open(filename)
+	exitOnError(err)
+	defer archive.Close()
+
+	applied, err := archive.Migrate()
+	for _, m := range applied {
+		log.Printf("%s migration %d (%s) to archive database[%s].\n", color.GreenString(constants.APPLIED), m.Version, m.Description, filename)
+	}
+	exitOnError(err)
+}
+
+func showSchemaVersion(db *database.Database) {
+	version, err := db.GetSchemaVersion()
+	exitOnError(err)
+
+	log.Printf("Database is up to date at schema version %d.\n", version)
+}
+
+func showMigrationStatus(db *database.Database) {
+	applied, err := db.GetAppliedMigrations()
+	exitOnError(err)
+
+	pending, err := db.GetPendingMigrations()
+	exitOnError(err)
+
+	version, err := db.GetSchemaVersion()
+	exitOnError(err)
+
+	var t table.Writer = table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.AppendHeader(table.Row{"Version", "Description", "Applied"})
+
+	for _, m := range applied {
+		t.AppendRow(table.Row{m.Version, m.Description, m.AppliedDatetime})
+	}
+
+	for _, m := range pending {
+		t.AppendRow(table.Row{m.Version, m.Description, color.YellowString("pending")})
+	}
+
+	log.Printf("Schema version %d of %d.\n\n", version, database.LatestSchemaVersion())
+	log.Println(t.Render())
+}
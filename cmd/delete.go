@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"timetracker/constants"
+
+	"github.com/agrison/go-commons-lang/stringUtils"
+	"github.com/fatih/color"
+	"github.com/golang-module/carbon/v2"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+)
+
+// deleteCmd represents the delete command.
+var deleteCmd = &cobra.Command{
+	Use:     "delete [uid...]",
+	Aliases: []string{"del", "rm"},
+	Short:   "Delete one or more entries",
+	Long: `Delete one or more entries, chosen by uid, from a list of a day's entries,
+or the last entry.  Since an entry's duration is measured from the entry before
+it, deleting an entry lengthens the entry after it.  You are shown how the
+durations change before anything is deleted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runDelete(cmd, args, openStore())
+	},
+}
+
+func init() {
+	deleteCmd.Flags().BoolP(constants.LAST, constants.EMPTY, false, "Delete the last entry.")
+	deleteCmd.Flags().BoolP(constants.TODAY, constants.EMPTY, false, "List all the entries for today to choose from.")
+	deleteCmd.Flags().StringP(constants.DAY, constants.EMPTY, constants.EMPTY, "List all the entries for the specified day, in "+constants.DATE_FORMAT+" format, to choose from.")
+	rootCmd.AddCommand(deleteCmd)
+}
+
+func runDelete(cmd *cobra.Command, args []string, store database.Store) {
+	last, _ := cmd.Flags().GetBool(constants.LAST)
+	today, _ := cmd.Flags().GetBool(constants.TODAY)
+	day, _ := cmd.Flags().GetString(constants.DAY)
+
+	var entries []models.Entry
+	if last {
+		entry, err := store.GetLastEntry()
+		exitOnError(err)
+		entries = append(entries, entry)
+	} else if today || !stringUtils.IsEmpty(day) {
+		var date carbon.Carbon = carbon.Now()
+		if !stringUtils.IsEmpty(day) {
+			date = carbon.Parse(day)
+			if date.Error != nil {
+				log.Fatalf("%s: Invalid day[%s].  Please use the %s format.\n", color.RedString(constants.FATAL_NORMAL_CASE), day, constants.DATE_FORMAT)
+			}
+		}
+
+		entries = chooseEntries(store, date)
+		if len(entries) == 0 {
+			log.Printf("No entry deleted.\n")
+			return
+		}
+	} else if len(args) > 0 {
+		for _, arg := range args {
+			uid, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				log.Fatalf("%s: Invalid uid[%s].\n", color.RedString(constants.FATAL_NORMAL_CASE), arg)
+			}
+
+			entry, err := store.GetEntry(uid)
+			exitOnError(err)
+			entries = append(entries, entry)
+		}
+	} else {
+		cmd.Help()
+		return
+	}
+
+	previewDelete(store, entries)
+
+	var label string = "\nDelete this entry?"
+	if len(entries) > 1 {
+		label = fmt.Sprintf("\nDelete these %d entries?", len(entries))
+	}
+
+	if yesNoPrompt(label) {
+		var uids []int64 = make([]int64, 0, len(entries))
+		for _, e := range entries {
+			uids = append(uids, e.Uid)
+		}
+
+		count, err := store.DeleteEntries(uids)
+		exitOnError(err)
+
+		log.Printf("%s %d of %d entries.  Use 'tt trash restore' to get them back.\n", color.GreenString(constants.DELETED), count, len(entries))
+	} else {
+		log.Printf("Nothing deleted.\n")
+	}
+}
+
+// Show the list of entries for the specified day and let the user choose the
+// ones to delete.
+func chooseEntries(store database.Store, date carbon.Carbon) []models.Entry {
+	r := bufio.NewReader(os.Stdin)
+
+	for {
+		entries, err := store.GetEntriesBetween(date.StartOfDay(), date.EndOfDay())
+		exitOnError(err)
+
+		if len(entries) == 0 {
+			log.Printf("There are no entries for %s.\n", date.Format(constants.CARBON_DATE_FORMAT))
+			return entries
+		}
+
+		var t table.Writer = table.NewWriter()
+		t.SetAutoIndex(true)
+		t.AppendHeader(table.Row{"Project", "Task(s)", "Date/Time"})
+		for _, entry := range entries {
+			t.AppendRow(table.Row{entry.Project, entry.GetTasksAsString(), entry.EntryDatetime})
+		}
+
+		log.Println(t.Render())
+
+		fmt.Print("Please enter the index number(s), separated by commas, of the entries you would like to delete; otherwise, ENTER to quit...\n")
+		s, _ := r.ReadString('\n')
+		s = strings.TrimSpace(s)
+
+		// If nothing was entered, there is nothing to delete.
+		if len(s) <= 0 {
+			return []models.Entry{}
+		}
+
+		var chosen []models.Entry
+		var valid bool = true
+		for _, value := range strings.Split(s, ",") {
+			// Validate that the user entered a number between 1 and the
+			// length of the entries.
+			i, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || i <= 0 || i > len(entries) {
+				valid = false
+				break
+			}
+
+			chosen = append(chosen, entries[i-1])
+		}
+
+		if !valid {
+			fmt.Printf("\nPlease enter a valid value.\n\n")
+			continue
+		}
+
+		return chosen
+	}
+}
+
+// Show the entries that will be deleted along with each remaining entry whose
+// duration will change once they are gone.
+func previewDelete(store database.Store, doomed []models.Entry) {
+	// A duration of zero is described in terms of round_to_minutes, the same
+	// way the report describes it.
+	var roundToMinutes int64 = viper.GetInt64(constants.ROUND_TO_MINUTES)
+
+	var doomedUids map[int64]bool = make(map[int64]bool)
+	var days map[string]carbon.Carbon = make(map[string]carbon.Carbon)
+	for _, e := range doomed {
+		doomedUids[e.Uid] = true
+		var date carbon.Carbon = carbon.Parse(e.EntryDatetime)
+		days[date.Format(constants.CARBON_DATE_FORMAT)] = date
+	}
+
+	// Since maps are not sorted in go, sort the keys and then access the map
+	// via those sorted keys.
+	var sortedKeys []string = make([]string, 0, len(days))
+	for key := range days {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	var t table.Writer = table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.AppendHeader(table.Row{constants.DATE_TIME_NORMAL_CASE, constants.PROJECT_NORMAL_CASE, constants.TASKS_NORMAL_CASE, "Old " + constants.DURATION_NORMAL_CASE, "New " + constants.DURATION_NORMAL_CASE})
+
+	for _, key := range sortedKeys {
+		var date carbon.Carbon = days[key]
+		before, err := store.GetEntriesBetween(date.StartOfDay(), date.EndOfDay())
+		exitOnError(err)
+
+		var after []models.Entry = make([]models.Entry, 0, len(before))
+		for _, e := range before {
+			if !doomedUids[e.Uid] {
+				after = append(after, e)
+			}
+		}
+
+		var oldDurations map[int64]models.UID = calculateDurations(before, carbon.Local, roundToMinutes)
+		var newDurations map[int64]models.UID = calculateDurations(after, carbon.Local, roundToMinutes)
+
+		for _, e := range before {
+			var oldDuration int64 = oldDurations[e.Uid].Duration
+			if doomedUids[e.Uid] {
+				t.AppendRow(table.Row{e.EntryDatetime, e.Project, e.GetTasksAsString(), secondsToHuman(oldDuration, roundToMinutes), color.RedString("deleted")})
+			} else if newDurations[e.Uid].Duration != oldDuration {
+				t.AppendRow(table.Row{e.EntryDatetime, e.Project, e.GetTasksAsString(), secondsToHuman(oldDuration, roundToMinutes), color.YellowString(secondsToHuman(newDurations[e.Uid].Duration, roundToMinutes))})
+			}
+		}
+	}
+
+	log.Printf("Deleting...\n\n")
+	log.Println(t.Render())
+}
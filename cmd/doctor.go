@@ -0,0 +1,462 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/fatih/color"
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// A finding is a problem found by one of the doctor's checks.  Fix is the
+// question asked before Repair is called to fix it.  Repair returns whether
+// anything was changed, and is nil when the problem cannot be fixed for you.
+type finding struct {
+	Problem string
+	Fix     string
+	Repair  func() bool
+}
+
+// A doctorCheck looks for one category of problem.
+type doctorCheck struct {
+	Name  string
+	Check func() []finding
+}
+
+// doctorCmd represents the doctor command.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Args:  cobra.ExactArgs(0),
+	Short: "Check the database for problems and fix them",
+	Long: `Check the database's integrity and scan its entries for problems: date/times
+that cannot be parsed, properties whose entry no longer exists, entries amended
+past other entries, near duplicate entries, and days without a ***hello.  Use
+--fix to fix the problems found, confirming each change.  Entries are moved to
+the trash rather than deleted, and every change can be undone with 'tt undo'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runDoctor(cmd, args)
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolP(constants.FIX, constants.EMPTY, false, "Fix the problems found, asking for confirmation before each change.")
+	doctorCmd.Flags().BoolP(constants.VACUUM, constants.EMPTY, false, "Rebuild the database file afterwards, reclaiming unused space.")
+	doctorCmd.Flags().DurationP(constants.WITHIN, constants.EMPTY, time.Minute, "Treat entries for the same project and task(s) this close together, e.g. 30s, as near duplicates.")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func runDoctor(cmd *cobra.Command, _ []string) {
+	fix, _ := cmd.Flags().GetBool(constants.FIX)
+	vacuum, _ := cmd.Flags().GetBool(constants.VACUUM)
+	within, _ := cmd.Flags().GetDuration(constants.WITHIN)
+
+	db, err := database.New(viper.GetString(constants.DATABASE_FILE))
+	exitOnError(err)
+	defer db.Close()
+
+	// There is no fixing corruption in place, but the entries can still be
+	// checked.
+	var corrupt bool = false
+	var found int = 0
+	err = db.IntegrityCheck()
+	if errors.Is(err, database.ErrCorrupt) {
+		log.Printf("Integrity check: %s\n", color.RedString("failed"))
+		log.Printf("  %s  Use 'tt restore' to restore a snapshot.\n", err.Error())
+		corrupt = true
+		found++
+	} else {
+		exitOnError(err)
+		log.Printf("Integrity check: %s\n", color.GreenString("ok"))
+	}
+
+	var fixed int = 0
+	var backedUp bool = false
+	for _, c := range doctorChecks(db, within) {
+		// Run each check just before fixing its findings, so it sees the fixes
+		// made for the checks before it.
+		var findings []finding = c.Check()
+		if len(findings) == 0 {
+			log.Printf("%s: %s\n", c.Name, color.GreenString("ok"))
+			continue
+		}
+
+		log.Printf("%s: %s\n", c.Name, color.YellowString("%d found", len(findings)))
+		found += len(findings)
+
+		for _, f := range findings {
+			log.Printf("  %s\n", f.Problem)
+			if !fix {
+				continue
+			}
+
+			if f.Repair == nil {
+				log.Printf("  Not fixed, use 'tt amend' if it is wrong.\n")
+				continue
+			}
+
+			if !yesNoPrompt("  " + f.Fix) {
+				log.Printf("  Not fixed.\n")
+				continue
+			}
+
+			if !backedUp {
+				backupBeforeDestructive(db, "doctor")
+				backedUp = true
+			}
+
+			if f.Repair() {
+				log.Printf("  %s.\n", color.GreenString("Fixed"))
+				fixed++
+			} else {
+				log.Printf("  Not fixed.\n")
+			}
+		}
+	}
+
+	if vacuum {
+		exitOnError(db.Vacuum())
+		log.Printf("%s database[%s].\n", color.GreenString("Vacuumed"), db.Filename)
+	}
+
+	if found == 0 {
+		log.Printf("No problems found.\n")
+	} else if fix {
+		log.Printf("%d problems found, %d fixed.\n", found, fixed)
+	} else {
+		log.Printf("%d problems found.  Use 'tt doctor --fix' to fix them.\n", found)
+	}
+
+	if corrupt {
+		os.Exit(constants.EXIT_CODE_CORRUPT)
+	}
+}
+
+// The doctor's checks, in the order they are run and fixed.  Parsing problems
+// come first, since the later checks skip entries that cannot be parsed.
+func doctorChecks(db *database.Database, within time.Duration) []doctorCheck {
+	return []doctorCheck{
+		{"Unparsable date/times", func() []finding { return checkUnparsableDatetimes(db) }},
+		{"Orphaned properties", func() []finding { return checkOrphanedProperties(db) }},
+		{"Out of order entries", func() []finding { return checkOutOfOrderEntries(db) }},
+		{"Near duplicate entries", func() []finding { return checkNearDuplicates(db, within) }},
+		{"Days without a " + constants.HELLO, func() []finding { return checkMissingHellos(db) }},
+	}
+}
+
+func checkUnparsableDatetimes(db *database.Database) []finding {
+	entries, err := db.GetEntriesMatching(database.EntryFilter{})
+	exitOnError(err)
+
+	var findings []finding
+	for _, e := range entries {
+		if _, ok := parseEntryDatetime(e); ok {
+			continue
+		}
+
+		var uid int64 = e.Uid
+		var f finding = finding{Problem: fmt.Sprintf("Entry[%d] date/time[%s] cannot be parsed. %s", uid, e.EntryDatetime, e.Dump(false))}
+
+		// Date/times recorded without an offset, or in another layout, can
+		// usually be recovered in the local time zone.
+		var recovered carbon.Carbon = carbon.Parse(e.EntryDatetime)
+		if recovered.Error == nil && !recovered.IsZero() {
+			var datetime string = recovered.ToRfc3339String()
+			f.Fix = fmt.Sprintf("Change entry[%d] date/time to [%s]?", uid, datetime)
+			f.Repair = func() bool {
+				exitOnError(db.UpdateEntry(models.NewEntry(uid, constants.EMPTY, constants.EMPTY, datetime), constants.DOCTOR))
+				return true
+			}
+		} else {
+			f.Fix = fmt.Sprintf("Move entry[%d] to the trash?", uid)
+			f.Repair = trashRepair(db, uid)
+		}
+
+		findings = append(findings, f)
+	}
+
+	return findings
+}
+
+func checkOrphanedProperties(db *database.Database) []finding {
+	properties, err := db.GetOrphanedProperties()
+	exitOnError(err)
+
+	var findings []finding
+	for _, p := range properties {
+		var rowid int64 = p.Rowid
+		findings = append(findings, finding{
+			Problem: fmt.Sprintf("Property[%s:%s] belongs to entry[%d], which does not exist.", p.Name, p.Value, p.EntryUid),
+			Fix:     fmt.Sprintf("Permanently delete property[%s:%s]?", p.Name, p.Value),
+			Repair: func() bool {
+				exitOnError(db.DeleteOrphanedProperty(rowid))
+				return true
+			},
+		})
+	}
+
+	return findings
+}
+
+// Find the entries whose date/time was last amended past one or more other
+// entries, which changes the work those entries are measured against.  Entries
+// amended before there was an entry_history have nothing to go back to, so the
+// entries whose uid is out of step with their date/time are reported as well.
+func checkOutOfOrderEntries(db *database.Database) []finding {
+	entries, err := db.GetEntriesMatching(database.EntryFilter{})
+	exitOnError(err)
+
+	changes, err := db.GetHistorySince(carbon.CreateFromTimestamp(0))
+	exitOnError(err)
+
+	// The changes are oldest first, so only each entry's last amendment of its
+	// date/time is kept.  Date/times the doctor itself changed were confirmed,
+	// so they are left alone.
+	var amended map[int64]models.Change = make(map[int64]models.Change)
+	for _, c := range changes {
+		if c.Field != constants.ENTRY_DATETIME {
+			continue
+		}
+
+		if c.Command == constants.DOCTOR {
+			delete(amended, c.EntryUid)
+		} else {
+			amended[c.EntryUid] = c
+		}
+	}
+
+	var findings []finding
+	var reported map[int64]bool = make(map[int64]bool)
+	for _, e := range entries {
+		c, found := amended[e.Uid]
+		if !found {
+			continue
+		}
+
+		// Skip entries changed since, e.g. by undo.
+		current, ok := parseEntryDatetime(e)
+		amendedTo, err := time.Parse(time.RFC3339, c.NewValue)
+		if !ok || err != nil || !amendedTo.Equal(current) {
+			continue
+		}
+
+		previous, err := time.Parse(time.RFC3339, c.OldValue)
+		if err != nil {
+			continue
+		}
+
+		var earliest, latest time.Time = previous, current
+		if current.Before(previous) {
+			earliest, latest = current, previous
+		}
+
+		var passed int = 0
+		for _, other := range entries {
+			t, ok := parseEntryDatetime(other)
+			if ok && other.Uid != e.Uid && t.After(earliest) && t.Before(latest) {
+				passed++
+			}
+		}
+
+		if passed == 0 {
+			continue
+		}
+
+		var uid int64 = e.Uid
+		var datetime string = c.OldValue
+		reported[uid] = true
+		findings = append(findings, finding{
+			Problem: fmt.Sprintf("Entry[%d] was amended from [%s] to [%s], past %d other entries. %s", uid, c.OldValue, c.NewValue, passed, e.Dump(false)),
+			Fix:     fmt.Sprintf("Change entry[%d] date/time back to [%s]?", uid, datetime),
+			Repair: func() bool {
+				exitOnError(db.UpdateEntry(models.NewEntry(uid, constants.EMPTY, constants.EMPTY, datetime), constants.DOCTOR))
+				return true
+			},
+		})
+	}
+
+	// The entries amended past others are left out, so the ones left are those
+	// there is no history for.  There is no telling what date/time they had,
+	// or whether they were added with --at on purpose, so they are only
+	// reported.
+	var remaining []models.Entry
+	for _, e := range entries {
+		if !reported[e.Uid] {
+			remaining = append(remaining, e)
+		}
+	}
+
+	for _, e := range entriesOutOfUidOrder(remaining) {
+		findings = append(findings, finding{
+			Problem: fmt.Sprintf("Entry[%d] is out of uid order with the entries around it, it was added with --at or amended before there was a history. %s", e.Uid, e.Dump(false)),
+		})
+	}
+
+	return findings
+}
+
+// Find the fewest entries, which are in date/time order, that have to be left
+// out for the rest of the entries' uids to be in the same order, i.e. the ones
+// outside the longest run of increasing uids.
+func entriesOutOfUidOrder(entries []models.Entry) []models.Entry {
+	var parsed []models.Entry
+	for _, e := range entries {
+		if _, ok := parseEntryDatetime(e); ok {
+			parsed = append(parsed, e)
+		}
+	}
+
+	// tails[n] is the index of the entry ending the run of n+1 increasing uids
+	// whose last uid is the lowest, and previous links each entry to the one
+	// before it in its run.
+	var tails []int
+	var previous []int = make([]int, len(parsed))
+	for i, e := range parsed {
+		n := sort.Search(len(tails), func(n int) bool { return parsed[tails[n]].Uid >= e.Uid })
+		previous[i] = -1
+		if n > 0 {
+			previous[i] = tails[n-1]
+		}
+
+		if n == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[n] = i
+		}
+	}
+
+	var inRun []bool = make([]bool, len(parsed))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = previous[i] {
+			inRun[i] = true
+		}
+	}
+
+	var outOfOrder []models.Entry
+	for i, e := range parsed {
+		if !inRun[i] {
+			outOfOrder = append(outOfOrder, e)
+		}
+	}
+
+	return outOfOrder
+}
+
+// Find the entries recorded for the same project and task(s) as the entry
+// right before them, within the specified amount of time.
+func checkNearDuplicates(db *database.Database, within time.Duration) []finding {
+	entries, err := db.GetEntriesMatching(database.EntryFilter{})
+	exitOnError(err)
+
+	var findings []finding
+	var previous models.Entry
+	var previousTime time.Time
+	var havePrevious bool = false
+	for _, e := range entries {
+		t, ok := parseEntryDatetime(e)
+		if !ok {
+			continue
+		}
+
+		if havePrevious && t.Sub(previousTime) <= within &&
+			strings.EqualFold(e.Project, previous.Project) &&
+			strings.EqualFold(e.GetTasksAsString(), previous.GetTasksAsString()) {
+			var uid int64 = e.Uid
+			findings = append(findings, finding{
+				Problem: fmt.Sprintf("Entry[%d] is %s after entry[%d] for the same project and task(s). %s", uid, secondsToHMS(int64(t.Sub(previousTime).Seconds())), previous.Uid, e.Dump(false)),
+				Fix:     fmt.Sprintf("Move entry[%d] to the trash?", uid),
+				Repair:  trashRepair(db, uid),
+			})
+
+			// Compare the next entry against the one that is being kept.
+			continue
+		}
+
+		previous = e
+		previousTime = t
+		havePrevious = true
+	}
+
+	return findings
+}
+
+// Find the days whose first entry is not a ***hello.  Without one, the time
+// before the day's first entry is measured from the previous day's last
+// entry, or midnight, and counted as work.
+func checkMissingHellos(db *database.Database) []finding {
+	entries, err := db.GetEntriesMatching(database.EntryFilter{})
+	exitOnError(err)
+
+	var findings []finding
+	var previousDay string = constants.EMPTY
+	for _, e := range entries {
+		t, ok := parseEntryDatetime(e)
+		if !ok {
+			continue
+		}
+
+		var first carbon.Carbon = carbon.CreateFromStdTime(t)
+		var day string = first.Format(constants.CARBON_DATE_FORMAT)
+		if day == previousDay {
+			continue
+		}
+
+		previousDay = day
+		if strings.EqualFold(e.Project, constants.HELLO) {
+			continue
+		}
+
+		findings = append(findings, finding{
+			Problem: fmt.Sprintf("Day[%s] has no %s before its first entry[%d] at %s. %s", day, constants.HELLO, e.Uid, first.Format(constants.CARBON_START_END_TIME_FORMAT), e.Dump(false)),
+			Fix:     fmt.Sprintf("Add a %s on %s?", constants.HELLO, day),
+			Repair: func() bool {
+				return addMissingHello(db, first)
+			},
+		})
+	}
+
+	return findings
+}
+
+// Ask when work started on the day of the first entry and add a ***hello then.
+func addMissingHello(db *database.Database, first carbon.Carbon) bool {
+	var day string = first.Format(constants.CARBON_DATE_FORMAT)
+	var input string = prompt("the time work started, in HH:MM format,", constants.EMPTY)
+	if input == constants.EMPTY {
+		return false
+	}
+
+	var hello carbon.Carbon = carbon.ParseByLayout(day+" "+input, constants.DATE_FORMAT+" 15:04")
+	if hello.Error != nil || !hello.Lt(first) {
+		log.Printf("  %s: Invalid time[%s].  It must be before %s.\n", color.YellowString("Warning"), input, first.Format(constants.CARBON_START_END_TIME_FORMAT))
+		return false
+	}
+
+	var entry models.Entry = models.NewEntry(constants.UNKNOWN_UID, constants.HELLO, constants.EMPTY, hello.ToRfc3339String())
+	exitOnError(db.InsertNewEntry(entry, constants.DOCTOR))
+	return true
+}
+
+// Move the entry with the specified uid to the trash.
+func trashRepair(db *database.Database, uid int64) func() bool {
+	return func() bool {
+		_, err := db.TrashEntries([]int64{uid}, constants.DOCTOR)
+		exitOnError(err)
+		return true
+	}
+}
+
+// Parse the entry's date/time the way it is stored, with its offset.
+func parseEntryDatetime(e models.Entry) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, e.EntryDatetime)
+	return t, err == nil
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+// Open a new database in a temporary file, seeded with the entries.
+func newTestDatabase(t *testing.T, entries ...models.Entry) *database.Database {
+	t.Helper()
+
+	db, err := database.New(filepath.Join(t.TempDir(), "timetracker.db"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.ImportEntries(entries, "seed"); err != nil {
+		t.Fatalf("ImportEntries() failed: %v", err)
+	}
+
+	return db
+}
+
+// Run the test against both the SQLite Database and the MemoryStore, each one
+// seeded with the entries.
+func forEachStore(t *testing.T, entries []models.Entry, test func(t *testing.T, store database.Store)) {
+	t.Run("Database", func(t *testing.T) { test(t, newTestDatabase(t, entries...)) })
+	t.Run("MemoryStore", func(t *testing.T) {
+		var store *database.MemoryStore = database.NewMemoryStore()
+		if _, err := store.ImportEntries(entries, "seed"); err != nil {
+			t.Fatalf("ImportEntries() failed: %v", err)
+		}
+
+		test(t, store)
+	})
+}
+
+// A new Entry with the properties, given as name and value pairs.
+func newTestEntry(uid int64, project string, note string, entryDatetime string, properties ...string) models.Entry {
+	var e models.Entry = models.NewEntry(uid, project, note, entryDatetime)
+	for i := 0; i < len(properties); i += 2 {
+		e.Properties = append(e.Properties, models.NewProperty(uid, properties[i], properties[i+1]))
+	}
+
+	return e
+}
+
+// The date/time, e.g. "2024-01-05 09:00", in the local time zone in RFC3339
+// format, the way entries are added.
+func localDatetime(datetime string) string {
+	return carbon.Parse(datetime).ToRfc3339String()
+}
+
+// Run one of the statements on the database, going around the store.
+func mustExec(t *testing.T, db *database.Database, statement string, args ...any) {
+	t.Helper()
+
+	if _, err := db.Conn.ExecContext(db.Context, statement, args...); err != nil {
+		t.Fatalf("ExecContext(%q) failed: %v", statement, err)
+	}
+}
+
+func TestDoctorChecks(t *testing.T) {
+	var entry = func(uid int64, project string, entryDatetime string) models.Entry {
+		return newTestEntry(uid, project, constants.EMPTY, entryDatetime, constants.TASK, "work")
+	}
+
+	// A day that has nothing wrong with it.
+	var day = []models.Entry{
+		entry(1, constants.HELLO, "2024-01-05T08:00:00Z"),
+		entry(2, "acme", "2024-01-05T09:00:00Z"),
+		entry(3, "globex", "2024-01-05T10:00:00Z"),
+		entry(4, "acme", "2024-01-05T11:00:00Z"),
+		entry(5, "globex", "2024-01-05T12:00:00Z"),
+	}
+
+	var tests = []struct {
+		name  string
+		check func(db *database.Database) []finding
+		seed  func(t *testing.T, db *database.Database)
+		// What each problem found must mention, in order, and whether
+		// running the repairs makes the check pass.
+		want     []string
+		repaired bool
+	}{
+		{
+			name:  "unparsable date/times",
+			check: checkUnparsableDatetimes,
+			seed: func(t *testing.T, db *database.Database) {
+				mustExec(t, db, "UPDATE entry SET entry_datetime = ? WHERE uid = ?;", "2024-01-05 09:00:00", 2)
+				mustExec(t, db, "UPDATE entry SET entry_datetime = ? WHERE uid = ?;", "yesterday-ish", 3)
+			},
+			want:     []string{"Entry[2]", "Entry[3]"},
+			repaired: true,
+		},
+		{
+			name:  "orphaned properties",
+			check: checkOrphanedProperties,
+			seed: func(t *testing.T, db *database.Database) {
+				// Foreign keys are enforced for every connection, so the
+				// property is orphaned on a connection of its own without them.
+				conn, err := db.Conn.Conn(db.Context)
+				if err != nil {
+					t.Fatalf("Conn() failed: %v", err)
+				}
+
+				defer conn.Close()
+				for _, statement := range []string{"PRAGMA foreign_keys = OFF;", "INSERT INTO property (entry_uid, name, value) VALUES (99, 'task', 'meeting');", "PRAGMA foreign_keys = ON;"} {
+					if _, err := conn.ExecContext(db.Context, statement); err != nil {
+						t.Fatalf("ExecContext(%q) failed: %v", statement, err)
+					}
+				}
+			},
+			want:     []string{"entry[99]"},
+			repaired: true,
+		},
+		{
+			name:  "amended past other entries",
+			check: checkOutOfOrderEntries,
+			seed: func(t *testing.T, db *database.Database) {
+				if err := db.UpdateEntry(models.NewEntry(2, constants.EMPTY, constants.EMPTY, "2024-01-05T10:30:00Z"), constants.AMEND); err != nil {
+					t.Fatalf("UpdateEntry() failed: %v", err)
+				}
+			},
+			want:     []string{"Entry[2] was amended"},
+			repaired: true,
+		},
+		{
+			name:  "amended within its neighbors",
+			check: checkOutOfOrderEntries,
+			seed: func(t *testing.T, db *database.Database) {
+				if err := db.UpdateEntry(models.NewEntry(2, constants.EMPTY, constants.EMPTY, "2024-01-05T09:30:00Z"), constants.AMEND); err != nil {
+					t.Fatalf("UpdateEntry() failed: %v", err)
+				}
+			},
+		},
+		{
+			name:  "amended before there was a history",
+			check: checkOutOfOrderEntries,
+			seed: func(t *testing.T, db *database.Database) {
+				mustExec(t, db, "UPDATE entry SET entry_datetime = ? WHERE uid = ?;", "2024-01-05T11:30:00Z", 2)
+			},
+			want: []string{"Entry[2] is out of uid order"},
+		},
+		{
+			name:  "amended before there was a history to before other entries",
+			check: checkOutOfOrderEntries,
+			seed: func(t *testing.T, db *database.Database) {
+				mustExec(t, db, "UPDATE entry SET entry_datetime = ? WHERE uid = ?;", "2024-01-05T08:30:00Z", 4)
+			},
+			want: []string{"Entry[4] is out of uid order"},
+		},
+		{
+			name:  "near duplicates",
+			check: func(db *database.Database) []finding { return checkNearDuplicates(db, time.Minute) },
+			seed: func(t *testing.T, db *database.Database) {
+				if _, err := db.ImportEntries([]models.Entry{entry(6, "globex", "2024-01-05T12:00:20Z")}, "seed"); err != nil {
+					t.Fatalf("ImportEntries() failed: %v", err)
+				}
+			},
+			want:     []string{"Entry[6] is 20 seconds after entry[5]"},
+			repaired: true,
+		},
+		{
+			name:  "days without a hello",
+			check: checkMissingHellos,
+			seed: func(t *testing.T, db *database.Database) {
+				if _, err := db.ImportEntries([]models.Entry{entry(6, "acme", "2024-01-06T09:00:00Z")}, "seed"); err != nil {
+					t.Fatalf("ImportEntries() failed: %v", err)
+				}
+			},
+			want: []string{"Day[2024-01-06]"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var db *database.Database = newTestDatabase(t, day...)
+			if findings := test.check(db); len(findings) != 0 {
+				t.Fatalf("a day with nothing wrong with it has %d problems, the first being %q", len(findings), findings[0].Problem)
+			}
+
+			test.seed(t, db)
+
+			var findings []finding = test.check(db)
+			if len(findings) != len(test.want) {
+				var problems []string
+				for _, f := range findings {
+					problems = append(problems, f.Problem)
+				}
+
+				t.Fatalf("found %q, want %d problems", problems, len(test.want))
+			}
+
+			for i, f := range findings {
+				if !strings.Contains(f.Problem, test.want[i]) {
+					t.Errorf("problem %d is %q, want it to mention %q", i, f.Problem, test.want[i])
+				}
+			}
+
+			if !test.repaired {
+				return
+			}
+
+			for _, f := range findings {
+				if !f.Repair() {
+					t.Fatalf("Repair() of %q changed nothing", f.Problem)
+				}
+			}
+
+			if findings := test.check(db); len(findings) != 0 {
+				t.Errorf("after the repairs, %q is still found", findings[0].Problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/agrison/go-commons-lang/stringUtils"
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command.
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export entries for other programs",
+	Long: `Export entries, written to standard output, in formats other programs can
+read.  By default every entry is exported, use --from, --to, and --project to
+export just some of them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	exportCmd.PersistentFlags().StringP(constants.FROM, constants.EMPTY, constants.EMPTY, "Only export the entries on or after this date, in "+constants.DATE_FORMAT+" format.")
+	exportCmd.PersistentFlags().StringP(constants.TO, constants.EMPTY, constants.EMPTY, "Only export the entries on or before this date, in "+constants.DATE_FORMAT+" format.")
+	exportCmd.PersistentFlags().StringP(constants.PROJECT, constants.EMPTY, constants.EMPTY, "Only export the entries for this project.")
+	rootCmd.AddCommand(exportCmd)
+}
+
+// Get the entries selected by the --from, --to, and --project flags, ordered
+// by date/time.
+func exportEntries(cmd *cobra.Command, store database.Store) []models.Entry {
+	fromStr, _ := cmd.Flags().GetString(constants.FROM)
+	toStr, _ := cmd.Flags().GetString(constants.TO)
+	project, _ := cmd.Flags().GetString(constants.PROJECT)
+
+	var filter database.EntryFilter = database.EntryFilter{Project: project}
+	if !stringUtils.IsEmpty(fromStr) {
+		filter.From = parseDateFlag(fromStr).StartOfDay().ToIso8601String()
+	}
+
+	if !stringUtils.IsEmpty(toStr) {
+		filter.To = parseDateFlag(toStr).EndOfDay().ToIso8601String()
+	}
+
+	entries, err := store.GetEntriesMatching(filter)
+	exitOnError(err)
+
+	return entries
+}
+
+// Calculate the durations of the entries the same way the report does, which
+// means looking at every entry, not just those for --project, on the days
+// from the first entry's to the last entry's.
+func exportDurations(store database.Store, entries []models.Entry, roundToMinutes int64) map[int64]models.UID {
+	if len(entries) == 0 {
+		return map[int64]models.UID{}
+	}
+
+	all, err := store.GetEntriesBetween(carbon.Parse(entries[0].EntryDatetime).StartOfDay(), carbon.Parse(entries[len(entries)-1].EntryDatetime).EndOfDay())
+	exitOnError(err)
+
+	return calculateDurations(all, carbon.Local, roundToMinutes)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+)
+
+// The layout of iCalendar date/times in UTC, e.g. "20240105T090000Z".
+const icsLayout string = "20060102T150405Z"
+
+// iCalendar lines longer than this many bytes must be folded.
+const icsLineLength int = 75
+
+// Escapes the characters that are special in iCalendar text.
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// exportIcsCmd represents the export ics command.
+var exportIcsCmd = &cobra.Command{
+	Use:   "ics",
+	Args:  cobra.NoArgs,
+	Short: "Export entries as an iCalendar (.ics) file",
+	Long: `Export entries as an iCalendar (.ics) file, e.g. 'tt export ics --from
+2024-01-01 --to 2024-01-31 > january.ics', to overlay the time you tracked on
+your calendar.  Each entry becomes an event from when the entry before it was
+made to when it was made, the same way 'tt report' measures it, with the
+project+task(s) as the summary, the note as the description, and the entry's
+url.  An event has only one url, so any other urls are added to the
+description.  Each event's uid comes from the entry's uid, and the
+database's, so importing the file again updates the events instead of
+duplicating them.  ***hello entries are left out, and so are ***break entries
+unless --include-breaks is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runExportIcs(cmd, args)
+	},
+}
+
+func init() {
+	exportIcsCmd.Flags().BoolP(constants.INCLUDE_BREAKS, constants.EMPTY, false, "Include ***break entries as free time events marked as breaks.")
+	exportCmd.AddCommand(exportIcsCmd)
+}
+
+func runExportIcs(cmd *cobra.Command, _ []string) {
+	includeBreaks, _ := cmd.Flags().GetBool(constants.INCLUDE_BREAKS)
+
+	var store = openStore()
+	var entries = exportEntries(cmd, store)
+	var durations = exportDurations(store, entries, 0)
+
+	// Entry uids are only unique within a database, so the database's uid
+	// keeps the events of different databases, e.g. profiles, apart.
+	storeUid, err := store.GetStoreUid()
+	exitOnError(err)
+
+	exitOnError(writeIcs(os.Stdout, entries, durations, storeUid, time.Now(), includeBreaks))
+}
+
+// Write the entries as an iCalendar with an event for each one, each event
+// lasting its entry's duration and stamped with now.
+func writeIcs(w io.Writer, entries []models.Entry, durations map[int64]models.UID, storeUid string, now time.Time, includeBreaks bool) error {
+	var buffered *bufio.Writer = bufio.NewWriter(w)
+	writeIcsLine(buffered, "BEGIN:VCALENDAR")
+	writeIcsLine(buffered, "VERSION:2.0")
+	writeIcsLine(buffered, "PRODID:-//Time Tracker//tt//EN")
+	writeIcsLine(buffered, "CALSCALE:GREGORIAN")
+	writeIcsLine(buffered, "X-WR-CALNAME:Time Tracker")
+	for _, e := range entries {
+		var isBreak bool = strings.EqualFold(e.Project, constants.BREAK)
+		if strings.EqualFold(e.Project, constants.HELLO) || (isBreak && !includeBreaks) {
+			continue
+		}
+
+		var end time.Time = carbon.Parse(e.EntryDatetime).StdTime()
+		var start time.Time = end.Add(-time.Duration(durations[e.Uid].Duration) * time.Second)
+
+		writeIcsLine(buffered, "BEGIN:VEVENT")
+		writeIcsLine(buffered, fmt.Sprintf("UID:entry-%d-%s@timetracker", e.Uid, storeUid))
+		writeIcsLine(buffered, "DTSTAMP:"+now.UTC().Format(icsLayout))
+		writeIcsLine(buffered, "DTSTART:"+start.UTC().Format(icsLayout))
+		writeIcsLine(buffered, "DTEND:"+end.UTC().Format(icsLayout))
+		writeIcsLine(buffered, "SUMMARY:"+icsEscaper.Replace(icsSummary(e)))
+
+		// An event can only have one URL, so the rest go in the description.
+		var urls []string = icsUrls(e)
+		var description []string
+		if e.Note != constants.EMPTY {
+			description = append(description, e.Note)
+		}
+
+		if len(urls) > 1 {
+			description = append(description, urls[1:]...)
+		}
+
+		if len(description) > 0 {
+			writeIcsLine(buffered, "DESCRIPTION:"+icsEscaper.Replace(strings.Join(description, "\n")))
+		}
+
+		if len(urls) > 0 {
+			writeIcsLine(buffered, "URL:"+urls[0])
+		}
+
+		// Breaks do not make you busy, so they are free time.
+		if isBreak {
+			writeIcsLine(buffered, "CATEGORIES:Break")
+			writeIcsLine(buffered, "TRANSP:TRANSPARENT")
+		}
+
+		writeIcsLine(buffered, "END:VEVENT")
+	}
+
+	writeIcsLine(buffered, "END:VCALENDAR")
+	return buffered.Flush()
+}
+
+// The summary, e.g. "acme+code review", the same way it is given to 'tt add'.
+func icsSummary(e models.Entry) string {
+	return strings.Join(append([]string{e.Project}, appendTasks([]string{}, e)...), constants.TASK_DELIMITER)
+}
+
+// The entry's urls, in the order they were added.
+func icsUrls(e models.Entry) []string {
+	var urls []string
+	for _, p := range e.Properties {
+		if strings.EqualFold(p.Name, constants.URL) {
+			urls = append(urls, p.Value)
+		}
+	}
+
+	return urls
+}
+
+// Write the line, folded so no line is longer than 75 bytes, without splitting
+// a UTF-8 character, and ended with CRLF as iCalendar requires.
+func writeIcsLine(w *bufio.Writer, line string) {
+	var length int = 0
+	for _, r := range line {
+		var size int = len(string(r))
+		if length+size > icsLineLength {
+			// Continuation lines start with a space, which counts towards
+			// their length.
+			w.WriteString("\r\n ")
+			length = 1
+		}
+
+		w.WriteRune(r)
+		length += size
+	}
+
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+func TestWriteIcsLine(t *testing.T) {
+	var tests = []struct {
+		name string
+		line string
+		want string
+	}{
+		{"short", "SUMMARY:acme", "SUMMARY:acme\r\n"},
+		{"75 bytes", strings.Repeat("a", 75), strings.Repeat("a", 75) + "\r\n"},
+		{"76 bytes", strings.Repeat("a", 76), strings.Repeat("a", 75) + "\r\n a\r\n"},
+		{"continuation lines count their space", strings.Repeat("a", 75+74+1), strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a\r\n"},
+		{"characters are not split", strings.Repeat("a", 74) + "é", strings.Repeat("a", 74) + "\r\n é\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			var w *bufio.Writer = bufio.NewWriter(&buffer)
+			writeIcsLine(w, test.line)
+			w.Flush()
+
+			if buffer.String() != test.want {
+				t.Errorf("writeIcsLine() wrote %q, want %q", buffer.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestWriteIcs(t *testing.T) {
+	var entries = []models.Entry{
+		newTestEntry(1, constants.HELLO, constants.EMPTY, localDatetime("2024-01-05 09:00:00")),
+		newTestEntry(2, "acme", "fixed a, b; c\nand a \\", localDatetime("2024-01-05 10:30:00"), constants.TASK, "code review", constants.URL, "https://example.com/pr/1", constants.URL, "https://example.com/pr/2"),
+		newTestEntry(3, constants.BREAK, "lunch", localDatetime("2024-01-05 12:00:00")),
+		newTestEntry(4, "globex", constants.EMPTY, localDatetime("2024-01-05 12:30:00"), constants.TASK, "calls", constants.TASK, "email"),
+	}
+
+	// The date/time in UTC, the way the events have it.
+	var utc = func(datetime string) string {
+		return carbon.Parse(datetime).StdTime().UTC().Format(icsLayout)
+	}
+
+	var acme []string = []string{
+		"BEGIN:VEVENT",
+		"UID:entry-2-store@timetracker",
+		"DTSTAMP:20240201T120000Z",
+		"DTSTART:" + utc("2024-01-05 09:00:00"),
+		"DTEND:" + utc("2024-01-05 10:30:00"),
+		"SUMMARY:acme+code review",
+		`DESCRIPTION:fixed a\, b\; c\nand a \\\nhttps://example.com/pr/2`,
+		"URL:https://example.com/pr/1",
+		"END:VEVENT",
+	}
+
+	var lunch []string = []string{
+		"BEGIN:VEVENT",
+		"UID:entry-3-store@timetracker",
+		"DTSTAMP:20240201T120000Z",
+		"DTSTART:" + utc("2024-01-05 10:30:00"),
+		"DTEND:" + utc("2024-01-05 12:00:00"),
+		"SUMMARY:***break",
+		"DESCRIPTION:lunch",
+		"CATEGORIES:Break",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+	}
+
+	var globex []string = []string{
+		"BEGIN:VEVENT",
+		"UID:entry-4-store@timetracker",
+		"DTSTAMP:20240201T120000Z",
+		"DTSTART:" + utc("2024-01-05 12:00:00"),
+		"DTEND:" + utc("2024-01-05 12:30:00"),
+		"SUMMARY:globex+calls+email",
+		"END:VEVENT",
+	}
+
+	var calendar = func(events ...[]string) string {
+		var lines []string = []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Time Tracker//tt//EN", "CALSCALE:GREGORIAN", "X-WR-CALNAME:Time Tracker"}
+		for _, event := range events {
+			lines = append(lines, event...)
+		}
+
+		return strings.Join(append(lines, "END:VCALENDAR"), "\r\n") + "\r\n"
+	}
+
+	var tests = []struct {
+		name          string
+		includeBreaks bool
+		want          string
+	}{
+		{"without breaks", false, calendar(acme, globex)},
+		{"with breaks", true, calendar(acme, lunch, globex)},
+	}
+
+	var now time.Time = time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := writeIcs(&buffer, entries, calculateDurations(entries, carbon.Local, 0), "store", now, test.includeBreaks); err != nil {
+				t.Fatalf("writeIcs() failed: %v", err)
+			}
+
+			checkLines(t, "calendar lines", strings.Split(buffer.String(), "\r\n"), strings.Split(test.want, "\r\n"))
+		})
+	}
+}
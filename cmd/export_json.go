@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// The format and version of the archives written by 'tt export json'.  The
+// version is bumped whenever a field is renamed or removed.
+const archiveFormat string = "timetracker"
+const archiveVersion int = 1
+
+// An archive is every exported entry, with all of its properties, exactly as
+// stored.
+type archive struct {
+	Format  string         `json:"format"`
+	Version int            `json:"version"`
+	Entries []archiveEntry `json:"entries"`
+}
+
+type archiveEntry struct {
+	Uid           int64             `json:"uid"`
+	Project       string            `json:"project"`
+	Note          string            `json:"note"`
+	EntryDatetime string            `json:"entry_datetime"`
+	Properties    []archiveProperty `json:"properties"`
+}
+
+type archiveProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// exportJsonCmd represents the export json command.
+var exportJsonCmd = &cobra.Command{
+	Use:   "json",
+	Args:  cobra.NoArgs,
+	Short: "Export entries as a JSON archive",
+	Long: `Export entries, with their uids, date/times, including the offsets they were
+recorded with, and all of their properties, as a JSON archive that
+'tt import json' can restore.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runExportJson(cmd, args)
+	},
+}
+
+func init() {
+	exportCmd.AddCommand(exportJsonCmd)
+}
+
+func runExportJson(cmd *cobra.Command, _ []string) {
+	exitOnError(writeArchive(os.Stdout, exportEntries(cmd, openStore())))
+}
+
+// Write the entries as an archive.
+func writeArchive(w io.Writer, entries []models.Entry) error {
+	var a archive = archive{Format: archiveFormat, Version: archiveVersion, Entries: make([]archiveEntry, 0, len(entries))}
+	for _, e := range entries {
+		var entry archiveEntry = archiveEntry{Uid: e.Uid, Project: e.Project, Note: e.Note, EntryDatetime: e.EntryDatetime, Properties: make([]archiveProperty, 0, len(e.Properties))}
+		for _, p := range e.Properties {
+			entry.Properties = append(entry.Properties, archiveProperty{Name: p.Name, Value: p.Value})
+		}
+
+		a.Entries = append(a.Entries, entry)
+	}
+
+	var encoder *json.Encoder = json.NewEncoder(w)
+	encoder.SetIndent(constants.EMPTY, "  ")
+	return encoder.Encode(a)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+)
+
+// Export every entry in the store as an archive.
+func exportArchive(t *testing.T, store database.Store) []byte {
+	t.Helper()
+
+	entries, err := store.GetEntriesMatching(database.EntryFilter{})
+	if err != nil {
+		t.Fatalf("GetEntriesMatching() failed: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	if err := writeArchive(&buffer, entries); err != nil {
+		t.Fatalf("writeArchive() failed: %v", err)
+	}
+
+	return buffer.Bytes()
+}
+
+// Import the archive into the store, the same way 'tt import json' does.
+func importArchive(t *testing.T, store database.Store, data []byte) {
+	t.Helper()
+
+	var filename string = filepath.Join(t.TempDir(), "archive.json")
+	if err := os.WriteFile(filename, data, 0600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	entries, warnings, err := readArchive(filename)
+	if err != nil {
+		t.Fatalf("readArchive() failed: %v", err)
+	}
+
+	if len(warnings) > 0 {
+		t.Fatalf("readArchive() warned %q", warnings)
+	}
+
+	importEntries(importJsonCmd, store, filename, entries, warnings)
+}
+
+func TestExportImportExportIsIdentical(t *testing.T) {
+	var entry = func(uid int64, project string, note string, entryDatetime string, properties ...string) models.Entry {
+		var e models.Entry = models.NewEntry(uid, project, note, entryDatetime)
+		for i := 0; i < len(properties); i += 2 {
+			e.Properties = append(e.Properties, models.NewProperty(uid, properties[i], properties[i+1]))
+		}
+
+		return e
+	}
+
+	// Gaps in the uids, as left by entries that were deleted, offsets other
+	// than the local one, several tasks, urls, and awkward text.
+	var original *database.MemoryStore = database.NewMemoryStore()
+	_, err := original.ImportEntries([]models.Entry{
+		entry(1, constants.HELLO, constants.EMPTY, "2024-01-05T08:00:00+01:00"),
+		entry(2, "acme", "customer's \"bug\"; fixed", "2024-01-05T09:30:00+01:00", constants.TASK, "code review", constants.URL, "https://example.com/pr/1"),
+		entry(5, "acme", constants.EMPTY, "2024-01-05T10:00:00+01:00", constants.TASK, "calls", constants.TASK, "email"),
+		entry(6, constants.BREAK, constants.EMPTY, "2024-01-05T11:00:00+01:00"),
+		entry(9, "Ünïcödé", "日本語 🚀", "2024-01-05T07:15:00-05:00", constants.TASK, "ñandú"),
+	}, "seed")
+	if err != nil {
+		t.Fatalf("ImportEntries() failed: %v", err)
+	}
+
+	var exported []byte = exportArchive(t, original)
+
+	var restored *database.MemoryStore = database.NewMemoryStore()
+	importArchive(t, restored, exported)
+	if again := exportArchive(t, restored); !bytes.Equal(again, exported) {
+		t.Fatalf("export, import, export changed the archive from\n%s\nto\n%s", exported, again)
+	}
+
+	// Importing the same archive again adds nothing.
+	importArchive(t, restored, exported)
+	if again := exportArchive(t, restored); !bytes.Equal(again, exported) {
+		t.Fatalf("importing the archive twice changed it from\n%s\nto\n%s", exported, again)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+)
+
+// The layout of the timestamps on Org-mode CLOCK lines, e.g. "2024-01-05 Fri
+// 09:00".
+const orgLayout string = "2006-01-02 Mon 15:04"
+
+// An orgClock is a CLOCK line, along with the note that follows it.
+type orgClock struct {
+	Start time.Time
+	End   time.Time
+	Note  string
+}
+
+// exportOrgCmd represents the export org command.
+var exportOrgCmd = &cobra.Command{
+	Use:   "org",
+	Args:  cobra.NoArgs,
+	Short: "Export entries as Emacs Org-mode CLOCK entries",
+	Long: `Export entries as an Emacs Org-mode headline for each project, with a headline
+for each of its tasks, holding a CLOCK line for each entry in a LOGBOOK
+drawer, newest first.  Each CLOCK runs from when the entry before it was made
+to when it was made, the same way 'tt report' measures it, with the entry's
+note after it.  ***hello and ***break entries are not time worked, so they
+are left out.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runExportOrg(cmd, args)
+	},
+}
+
+func init() {
+	exportCmd.AddCommand(exportOrgCmd)
+}
+
+func runExportOrg(cmd *cobra.Command, _ []string) {
+	var store = openStore()
+	var entries = exportEntries(cmd, store)
+	exitOnError(writeOrg(os.Stdout, entries, exportDurations(store, entries, 0)))
+}
+
+// Write the entries as a headline for each project, and each of its tasks,
+// holding the entries' clocks, each clock lasting its entry's duration.
+func writeOrg(w io.Writer, entries []models.Entry, durations map[int64]models.UID) error {
+	var buffered *bufio.Writer = bufio.NewWriter(w)
+
+	// Consolidate the clocks by project and then by task(s), as they will be
+	// headlines, so names that only differ in what a headline leaves out share
+	// one.
+	var clocks map[string]map[string][]orgClock = make(map[string]map[string][]orgClock)
+	for _, e := range entries {
+		if strings.EqualFold(e.Project, constants.HELLO) || strings.EqualFold(e.Project, constants.BREAK) {
+			continue
+		}
+
+		var end time.Time = carbon.Parse(e.EntryDatetime).StdTime()
+		var clock orgClock = orgClock{Start: end.Add(-time.Duration(durations[e.Uid].Duration) * time.Second), End: end, Note: e.Note}
+		var project string = orgHeadline(e.Project)
+		var tasks string = orgHeadline(strings.Join(appendTasks([]string{}, e), constants.TASK_DELIMITER))
+		if _, found := clocks[project]; !found {
+			clocks[project] = make(map[string][]orgClock)
+		}
+
+		clocks[project][tasks] = append(clocks[project][tasks], clock)
+	}
+
+	for _, project := range sortedNames(clocks) {
+		fmt.Fprintf(buffered, "* %s\n", project)
+		for _, tasks := range sortedNames(clocks[project]) {
+			// Entries without tasks are clocked on the project itself.
+			if tasks != constants.EMPTY {
+				fmt.Fprintf(buffered, "** %s\n", tasks)
+			}
+
+			writeOrgLogbook(buffered, clocks[project][tasks])
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// Write the clocks, newest first, in a LOGBOOK drawer.
+func writeOrgLogbook(w io.Writer, clocks []orgClock) {
+	fmt.Fprintf(w, ":LOGBOOK:\n")
+	for i := len(clocks) - 1; i >= 0; i-- {
+		var c orgClock = clocks[i]
+		var minutes int = int(c.End.Truncate(time.Minute).Sub(c.Start.Truncate(time.Minute)).Minutes())
+		fmt.Fprintf(w, "CLOCK: [%s]--[%s] => %2d:%02d\n", c.Start.Format(orgLayout), c.End.Format(orgLayout), minutes/60, minutes%60)
+		if note := strings.Join(strings.Fields(c.Note), " "); note != constants.EMPTY {
+			fmt.Fprintf(w, "- %s\n", note)
+		}
+	}
+
+	fmt.Fprintf(w, ":END:\n")
+}
+
+// Get the map's keys in alphabetical order.
+func sortedNames[V any](m map[string]V) []string {
+	var keys []string = make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// A headline is a single line, and one starting with "*" would be read back
+// as a deeper headline.
+func orgHeadline(text string) string {
+	return strings.TrimLeft(strings.Join(strings.Fields(text), " "), "* ")
+}
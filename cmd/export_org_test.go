@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+func TestWriteOrg(t *testing.T) {
+	var entries = []models.Entry{
+		newTestEntry(1, constants.HELLO, constants.EMPTY, localDatetime("2024-01-05 09:00:00")),
+		newTestEntry(2, "acme", "looked at\nthe PR", localDatetime("2024-01-05 10:30:00"), constants.TASK, "code review"),
+		newTestEntry(3, "acme", constants.EMPTY, localDatetime("2024-01-05 11:00:00")),
+		newTestEntry(4, constants.BREAK, "lunch", localDatetime("2024-01-05 12:00:00")),
+		newTestEntry(5, "** globex", constants.EMPTY, localDatetime("2024-01-05 12:15:00"), constants.TASK, "calls", constants.TASK, "email"),
+		newTestEntry(6, "acme", "again", localDatetime("2024-01-05 14:00:00"), constants.TASK, "code review"),
+		newTestEntry(7, constants.HELLO, constants.EMPTY, localDatetime("2024-01-06 08:00:00")),
+		newTestEntry(8, "acme", constants.EMPTY, localDatetime("2024-01-06 18:30:00"), constants.TASK, "code review"),
+	}
+
+	const want string = `* acme
+:LOGBOOK:
+CLOCK: [2024-01-05 Fri 10:30]--[2024-01-05 Fri 11:00] =>  0:30
+:END:
+** code review
+:LOGBOOK:
+CLOCK: [2024-01-06 Sat 08:00]--[2024-01-06 Sat 18:30] => 10:30
+CLOCK: [2024-01-05 Fri 12:15]--[2024-01-05 Fri 14:00] =>  1:45
+- again
+CLOCK: [2024-01-05 Fri 09:00]--[2024-01-05 Fri 10:30] =>  1:30
+- looked at the PR
+:END:
+* globex
+** calls+email
+:LOGBOOK:
+CLOCK: [2024-01-05 Fri 12:00]--[2024-01-05 Fri 12:15] =>  0:15
+:END:
+`
+
+	var buffer bytes.Buffer
+	if err := writeOrg(&buffer, entries, calculateDurations(entries, carbon.Local, 0)); err != nil {
+		t.Fatalf("writeOrg() failed: %v", err)
+	}
+
+	if buffer.String() != want {
+		t.Fatalf("the Org-mode file is\n%s\nwant\n%s", buffer.String(), want)
+	}
+
+	// Importing the file gets the entries back, other than the note's line
+	// break and the headline's stars.
+	var filename string = filepath.Join(t.TempDir(), "time.org")
+	if err := os.WriteFile(filename, buffer.Bytes(), 0600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	intervals, warnings, err := readOrg(filename)
+	if err != nil {
+		t.Fatalf("readOrg() failed: %v", err)
+	}
+
+	if len(warnings) > 0 {
+		t.Errorf("readOrg() warned %q", warnings)
+	}
+
+	checkLines(t, "imported entries", importIntoEmptyStore(t, importOrgCmd, intervals), []string{
+		"2024-01-05 09:00 ***hello",
+		"2024-01-05 10:30 acme+code review (looked at the PR)",
+		"2024-01-05 11:00 acme",
+		"2024-01-05 12:00 ***break",
+		"2024-01-05 12:15 globex+calls+email",
+		"2024-01-05 14:00 acme+code review (again)",
+		"2024-01-06 08:00 ***hello",
+		"2024-01-06 18:30 acme+code review",
+	})
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// The layout of the date/times on timeclock i and o lines.
+const timeclockLayout string = "Y/m/d H:i:s"
+
+// exportTimeclockCmd represents the export timeclock command.
+var exportTimeclockCmd = &cobra.Command{
+	Use:   "timeclock",
+	Args:  cobra.NoArgs,
+	Short: "Export entries as a ledger/hledger timeclock file",
+	Long: `Export entries as a timeclock file that ledger and hledger can read, e.g.
+'tt export timeclock > time.timeclock' and then 'hledger -f time.timeclock
+balance'.  Each entry becomes a clock-in, where the entry before it ended, and
+a clock-out, where it ended, for the account 'project:task', with the entry's
+note as the description.  ***hello and ***break entries are not time worked,
+so they are left out.  With --round, each entry's duration is rounded to the
+configured round_to_minutes, the same way 'tt report' rounds it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runExportTimeclock(cmd, args)
+	},
+}
+
+func init() {
+	exportTimeclockCmd.Flags().BoolP(constants.ROUND, constants.EMPTY, false, "Round each entry's duration to the configured round_to_minutes.")
+	exportCmd.AddCommand(exportTimeclockCmd)
+}
+
+func runExportTimeclock(cmd *cobra.Command, _ []string) {
+	rounded, _ := cmd.Flags().GetBool(constants.ROUND)
+
+	var roundToMinutes int64 = 0
+	if rounded {
+		roundToMinutes = viper.GetInt64(constants.ROUND_TO_MINUTES)
+	}
+
+	var store = openStore()
+	var entries = exportEntries(cmd, store)
+	exitOnError(writeTimeclock(os.Stdout, entries, exportDurations(store, entries, roundToMinutes), rounded))
+}
+
+// Write the entries as timeclock clock-ins and clock-outs, each entry lasting
+// its duration, or its rounded duration when rounded.
+func writeTimeclock(w io.Writer, entries []models.Entry, durations map[int64]models.UID, rounded bool) error {
+	var buffered *bufio.Writer = bufio.NewWriter(w)
+
+	// Rounding can make an entry end after the next one starts, so the next
+	// one is clocked in when the one before it is clocked out instead.
+	var clockedOut carbon.Carbon
+	for _, e := range entries {
+		if strings.EqualFold(e.Project, constants.HELLO) || strings.EqualFold(e.Project, constants.BREAK) {
+			continue
+		}
+
+		var end carbon.Carbon = carbon.Parse(e.EntryDatetime)
+		var start carbon.Carbon = end.SubSeconds(int(durations[e.Uid].Duration))
+		if rounded {
+			if !clockedOut.IsZero() && clockedOut.Gt(start) {
+				start = clockedOut
+			}
+
+			end = start.AddSeconds(int(durations[e.Uid].RoundedDuration))
+			clockedOut = end
+		}
+
+		fmt.Fprintf(buffered, "i %s %s", start.Format(timeclockLayout), timeclockAccount(e.Project, appendTasks([]string{}, e)))
+		if note := timeclockText(e.Note); note != constants.EMPTY {
+			fmt.Fprintf(buffered, "  %s", note)
+		}
+
+		fmt.Fprintf(buffered, "\no %s\n", end.Format(timeclockLayout))
+	}
+
+	return buffered.Flush()
+}
+
+// The account, e.g. "acme:code review", for the project and tasks.  Multiple
+// tasks are joined with "+", the same way they are given to 'tt add'.
+func timeclockAccount(project string, tasks []string) string {
+	var account string = timeclockText(project)
+	if len(tasks) > 0 {
+		account += ":" + timeclockText(strings.Join(tasks, constants.TASK_DELIMITER))
+	}
+
+	return account
+}
+
+// Put the text on a single line, without the double spaces that would end an
+// account name.
+func timeclockText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
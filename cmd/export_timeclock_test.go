@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+func TestWriteTimeclock(t *testing.T) {
+	var tests = []struct {
+		name    string
+		entries []models.Entry
+		// Rounded to this many minutes, unless zero.
+		roundToMinutes int64
+		want           string
+	}{
+		{
+			name: "a day",
+			entries: []models.Entry{
+				newTestEntry(1, constants.HELLO, constants.EMPTY, localDatetime("2024-01-05 09:00:00")),
+				newTestEntry(2, "acme", "looked at  the\nPR", localDatetime("2024-01-05 10:30:00"), constants.TASK, "code review"),
+				newTestEntry(3, constants.BREAK, "lunch", localDatetime("2024-01-05 11:00:00")),
+				newTestEntry(4, "big  client", constants.EMPTY, localDatetime("2024-01-05 11:20:45"), constants.TASK, "calls", constants.TASK, "email"),
+				newTestEntry(5, "acme", constants.EMPTY, localDatetime("2024-01-05 12:00:00")),
+			},
+			want: `i 2024/01/05 09:00:00 acme:code review  looked at the PR
+o 2024/01/05 10:30:00
+i 2024/01/05 11:00:00 big client:calls+email
+o 2024/01/05 11:20:45
+i 2024/01/05 11:20:45 acme
+o 2024/01/05 12:00:00
+`,
+		},
+		{
+			name: "rounded",
+			entries: []models.Entry{
+				newTestEntry(1, constants.HELLO, constants.EMPTY, localDatetime("2024-01-05 09:00:00")),
+				newTestEntry(2, "acme", constants.EMPTY, localDatetime("2024-01-05 09:40:00"), constants.TASK, "calls"),
+				newTestEntry(3, "acme", constants.EMPTY, localDatetime("2024-01-05 10:10:00"), constants.TASK, "email"),
+			},
+			roundToMinutes: 15,
+			want: `i 2024/01/05 09:00:00 acme:calls
+o 2024/01/05 09:30:00
+i 2024/01/05 09:40:00 acme:email
+o 2024/01/05 10:10:00
+`,
+		},
+		{
+			name:    "nothing to export",
+			entries: []models.Entry{newTestEntry(1, constants.HELLO, constants.EMPTY, localDatetime("2024-01-05 09:00:00"))},
+			want:    constants.EMPTY,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			var durations map[int64]models.UID = calculateDurations(test.entries, carbon.Local, test.roundToMinutes)
+			if err := writeTimeclock(&buffer, test.entries, durations, test.roundToMinutes > 0); err != nil {
+				t.Fatalf("writeTimeclock() failed: %v", err)
+			}
+
+			if buffer.String() != test.want {
+				t.Errorf("the timeclock file is\n%s\nwant\n%s", buffer.String(), test.want)
+			}
+		})
+	}
+}
@@ -31,7 +31,7 @@ var helloCmd = &cobra.Command{
 command.  It informs timetracker that you would like it to start tracking
 your time.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runHello(cmd, args)
+		runHello(cmd, args, openStore())
 	},
 }
 
@@ -64,7 +64,7 @@ func greetings(c carbon.Carbon) string {
 	return value
 }
 
-func runHello(cmd *cobra.Command, _ []string) {
+func runHello(cmd *cobra.Command, _ []string, store database.Store) {
 	// Get the current date/time.
 	var helloTime carbon.Carbon = carbon.Now()
 
@@ -91,6 +91,5 @@ func runHello(cmd *cobra.Command, _ []string) {
 	}
 
 	// Write the new Entry to the database.
-	db := database.New(viper.GetString(constants.DATABASE_FILE))
-	db.InsertNewEntry(entry)
+	exitOnError(store.InsertNewEntry(entry, cmd.Name()))
 }
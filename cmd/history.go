@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"log"
+	"strconv"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/agrison/go-commons-lang/stringUtils"
+	"github.com/fatih/color"
+	"github.com/golang-module/carbon/v2"
+	"github.com/ijt/go-anytime"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command.
+var historyCmd = &cobra.Command{
+	Use:   "history [uid]",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Show how entries were changed",
+	Long: `Every change made to an entry by the amend and stretch commands is recorded,
+along with the old value, new value, when it was changed, and the command used.
+Show the change trail of the entry with the specified uid, or use --since to
+show all the changes made since a date/time.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runHistory(cmd, args, openStore())
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringP(constants.SINCE, constants.EMPTY, constants.EMPTY, "Show the changes made since this date/time, e.g., 2024-04-15 or '2 weeks ago'.")
+	rootCmd.AddCommand(historyCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string, store database.Store) {
+	since, _ := cmd.Flags().GetString(constants.SINCE)
+
+	var changes []models.Change
+	if len(args) > 0 {
+		uid, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			log.Fatalf("%s: Invalid uid[%s].\n", color.RedString(constants.FATAL_NORMAL_CASE), args[0])
+		}
+
+		changes, err = store.GetHistory(uid)
+		exitOnError(err)
+	} else if !stringUtils.IsEmpty(since) {
+		var sinceTime carbon.Carbon = carbon.Parse(since)
+		if sinceTime.Error != nil {
+			atTime, err := anytime.Parse(since, time.Now())
+			if err != nil {
+				log.Fatalf("%s: Invalid since[%s].  Please use the %s format or a natural language time.\n", color.RedString(constants.FATAL_NORMAL_CASE), since, constants.DATE_FORMAT)
+			}
+
+			sinceTime = carbon.CreateFromStdTime(atTime)
+		}
+
+		var err error
+		changes, err = store.GetHistorySince(sinceTime)
+		exitOnError(err)
+	} else {
+		cmd.Help()
+		return
+	}
+
+	if len(changes) == 0 {
+		log.Printf("No changes found.\n")
+		return
+	}
+
+	var t table.Writer = table.NewWriter()
+	t.AppendHeader(table.Row{"Uid", "Changed", "Command", "Field", "Old", "New"})
+	for _, c := range changes {
+		var changed string = carbon.Parse(c.ChangedDatetime).SetTimezone(carbon.Local).ToDateTimeString()
+		t.AppendRow(table.Row{c.EntryUid, changed, c.Command, c.Field, c.OldValue, c.NewValue})
+	}
+
+	log.Println(t.Render())
+}
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/fatih/color"
+	"github.com/golang-module/carbon/v2"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// Gaps between intervals shorter than this are not worth a ***break.
+const minimumBreak time.Duration = time.Minute
+
+// An interval is a span of work as recorded by the time trackers that record
+// a start and an end, rather than just an end like Time Tracker does.
+type interval struct {
+	Start   time.Time
+	End     time.Time
+	Project string
+	Tasks   []string
+	Note    string
+	Url     string
+}
+
+// importCmd represents the import command.
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import entries from other time trackers",
+	Long: `Import entries from other time trackers.  Entries that already exist, with
+the same date/time, project, and task(s), are skipped, so importing the same
+file twice does not duplicate anything.  Everything imported at once can be
+undone with a single 'tt undo'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	importCmd.PersistentFlags().BoolP(constants.DRY_RUN, constants.EMPTY, false, "Do not actually import anything, but show what would be imported.")
+	rootCmd.AddCommand(importCmd)
+}
+
+// Convert the intervals into entries.  Each interval becomes an entry at its
+// end.  A ***hello is added at the start of an interval that nothing, neither
+// an existing entry nor another interval, comes before on its day, and a
+// ***break at the start of any other interval that does not start where the
+// entry before it ended.  Overlapping intervals are reported as warnings.
+func intervalsToEntries(intervals []interval, existing []models.Entry) ([]models.Entry, []string) {
+	sort.SliceStable(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	// The date/times of the existing entries, by day, and the entries
+	// themselves.
+	var days map[string][]time.Time = make(map[string][]time.Time)
+	var seen map[string]bool = make(map[string]bool)
+	for _, e := range existing {
+		var at carbon.Carbon = carbon.Parse(e.EntryDatetime)
+		days[at.Format(constants.CARBON_DATE_FORMAT)] = append(days[at.Format(constants.CARBON_DATE_FORMAT)], at.StdTime())
+		seen[importKey(e)] = true
+	}
+
+	var entries []models.Entry
+	var warnings []string
+	var previous time.Time
+	for _, iv := range intervals {
+		var start carbon.Carbon = carbon.CreateFromStdTime(iv.Start)
+		var day string = start.Format(constants.CARBON_DATE_FORMAT)
+
+		var entry models.Entry = models.NewEntry(constants.UNKNOWN_UID, iv.Project, iv.Note, carbon.CreateFromStdTime(iv.End).ToRfc3339String())
+		for _, task := range iv.Tasks {
+			entry.AddEntryProperty(constants.TASK, task)
+		}
+
+		if iv.Url != constants.EMPTY {
+			entry.AddEntryProperty(constants.URL, iv.Url)
+		}
+
+		// An interval imported before already has whatever ***hello or
+		// ***break it needs.
+		if seen[importKey(entry)] {
+			entries = append(entries, entry)
+			if iv.End.After(previous) {
+				previous = iv.End
+			}
+
+			continue
+		}
+
+		// The end of the latest entry, existing or imported, on the same day
+		// at or before the start of the interval.
+		var last time.Time
+		if !previous.IsZero() && carbon.CreateFromStdTime(previous).Format(constants.CARBON_DATE_FORMAT) == day {
+			last = previous
+		}
+
+		for _, at := range days[day] {
+			if !at.After(iv.Start) && at.After(last) {
+				last = at
+			}
+		}
+
+		if last.IsZero() {
+			entries = append(entries, models.NewEntry(constants.UNKNOWN_UID, constants.HELLO, constants.EMPTY, start.ToRfc3339String()))
+		} else if iv.Start.Sub(last) >= minimumBreak {
+			entries = append(entries, models.NewEntry(constants.UNKNOWN_UID, constants.BREAK, constants.EMPTY, start.ToRfc3339String()))
+		} else if iv.Start.Before(last) {
+			warnings = append(warnings, fmt.Sprintf("Interval from %s to %s for project[%s] overlaps the one before it, so it is measured from %s.", start.ToDateTimeString(), carbon.CreateFromStdTime(iv.End).ToDateTimeString(), iv.Project, carbon.CreateFromStdTime(last).ToDateTimeString()))
+		}
+
+		entries = append(entries, entry)
+		if iv.End.After(previous) {
+			previous = iv.End
+		}
+	}
+
+	return entries, warnings
+}
+
+// Import the intervals read from source.  Intervals that overlap the time
+// already covered by existing entries would change those entries' durations,
+// so they are skipped and reported as conflicts instead.  Intervals imported
+// before are not conflicts, they are skipped as duplicates.
+func importIntervals(cmd *cobra.Command, store database.Store, source string, intervals []interval, warnings []string) {
+	var kept []interval
+	var t table.Writer = table.NewWriter()
+	t.AppendHeader(table.Row{"Imported", constants.PROJECT_NORMAL_CASE, constants.TASKS_NORMAL_CASE, "Existing Uid", "Existing", constants.PROJECT_NORMAL_CASE, constants.TASKS_NORMAL_CASE})
+	for _, iv := range intervals {
+		conflict, found := findConflict(store, iv)
+		if !found {
+			kept = append(kept, iv)
+			continue
+		}
+
+		var span string = carbon.CreateFromStdTime(iv.Start).ToDateTimeString() + " to " + carbon.CreateFromStdTime(iv.End).ToTimeString()
+		t.AppendRow(table.Row{span, iv.Project, strings.Join(iv.Tasks, ", "), conflict.Uid, conflict.EntryDatetime, conflict.Project, conflict.GetTasksAsString()})
+	}
+
+	if t.Length() > 0 {
+		log.Printf("%s: %d intervals overlap existing entries and are skipped.\n", color.YellowString("Conflicts"), t.Length())
+		log.Println(t.Render())
+	}
+
+	// The ***hello and ***break entries have to fit in with the entries
+	// already on the days being imported.
+	var existing []models.Entry
+	if len(kept) > 0 {
+		var first time.Time = kept[0].Start
+		var last time.Time = kept[0].End
+		for _, iv := range kept {
+			if iv.Start.Before(first) {
+				first = iv.Start
+			}
+
+			if iv.End.After(last) {
+				last = iv.End
+			}
+		}
+
+		var err error
+		existing, err = store.GetEntriesBetween(carbon.CreateFromStdTime(first).StartOfDay(), carbon.CreateFromStdTime(last).EndOfDay())
+		exitOnError(err)
+	}
+
+	entries, overlaps := intervalsToEntries(kept, existing)
+	importEntries(cmd, store, source, entries, append(warnings, overlaps...))
+}
+
+// Find the existing entry, other than a ***hello, whose time overlaps the
+// interval.  An entry's time runs from the entry before it on the same day, or
+// midnight, to the entry itself.
+func findConflict(store database.Store, iv interval) (models.Entry, bool) {
+	var start carbon.Carbon = carbon.CreateFromStdTime(iv.Start)
+	var end carbon.Carbon = carbon.CreateFromStdTime(iv.End)
+	existing, err := store.GetEntriesBetween(start.StartOfDay(), end.EndOfDay())
+	exitOnError(err)
+
+	var imported models.Entry = models.NewEntry(constants.UNKNOWN_UID, iv.Project, iv.Note, end.ToRfc3339String())
+	for _, task := range iv.Tasks {
+		imported.AddEntryProperty(constants.TASK, task)
+	}
+
+	var key string = importKey(imported)
+	for _, e := range existing {
+		if importKey(e) == key {
+			return models.Entry{}, false
+		}
+	}
+
+	var previous carbon.Carbon
+	for i, e := range existing {
+		var current carbon.Carbon = carbon.Parse(e.EntryDatetime)
+		var from carbon.Carbon = current.StartOfDay()
+		if i > 0 && previous.Format(constants.CARBON_DATE_FORMAT) == current.Format(constants.CARBON_DATE_FORMAT) {
+			from = previous
+		}
+
+		previous = current
+		if !strings.EqualFold(e.Project, constants.HELLO) && start.Lt(current) && end.Gt(from) {
+			return e, true
+		}
+	}
+
+	return models.Entry{}, false
+}
+
+// The key two entries share when they are duplicates of each other.
+func importKey(e models.Entry) string {
+	t, err := time.Parse(time.RFC3339, e.EntryDatetime)
+	if err != nil {
+		return e.EntryDatetime
+	}
+
+	return fmt.Sprintf("%d|%s|%s", t.Unix(), strings.ToLower(e.Project), strings.ToLower(e.GetTasksAsString()))
+}
+
+// Import the entries read from source, skipping those that already exist,
+// and summarize what was, or with --dry-run would be, imported.  Warnings are
+// the problems the importer ran into, e.g. lines it could not parse.
+func importEntries(cmd *cobra.Command, store database.Store, source string, entries []models.Entry, warnings []string) {
+	dryRun, _ := cmd.Flags().GetBool(constants.DRY_RUN)
+
+	for _, w := range warnings {
+		log.Printf("%s: %s\n", color.YellowString("Warning"), w)
+	}
+
+	if len(entries) == 0 {
+		log.Printf("Nothing to import from [%s].\n", source)
+		return
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return carbon.Parse(entries[i].EntryDatetime).Lt(carbon.Parse(entries[j].EntryDatetime))
+	})
+
+	var first carbon.Carbon = carbon.Parse(entries[0].EntryDatetime)
+	var last carbon.Carbon = carbon.Parse(entries[len(entries)-1].EntryDatetime)
+	existing, err := store.GetEntriesBetween(first, last)
+	exitOnError(err)
+
+	var seen map[string]bool = make(map[string]bool)
+	for _, e := range existing {
+		seen[importKey(e)] = true
+	}
+
+	var fresh []models.Entry
+	var duplicates, hellos, breaks int
+	for _, e := range entries {
+		var key string = importKey(e)
+		if seen[key] {
+			duplicates++
+			continue
+		}
+
+		seen[key] = true
+		fresh = append(fresh, e)
+		if strings.EqualFold(e.Project, constants.HELLO) {
+			hellos++
+		} else if strings.EqualFold(e.Project, constants.BREAK) {
+			breaks++
+		}
+	}
+
+	log.Printf("Read %d entries, from %s to %s, from [%s].\n", len(entries), first.ToDateString(), last.ToDateString(), source)
+	log.Printf("  %d new entries, including %d %s and %d %s entries.\n", len(fresh), hellos, constants.HELLO, breaks, constants.BREAK)
+	log.Printf("  %d entries already exist and are skipped.\n", duplicates)
+	if len(warnings) > 0 {
+		log.Printf("  %d warnings.\n", len(warnings))
+	}
+
+	if dryRun {
+		log.Printf("Dry run, %d entries would have been imported.\n", len(fresh))
+		return
+	}
+
+	if len(fresh) == 0 {
+		log.Printf("Nothing imported.\n")
+		return
+	}
+
+	count, err := store.ImportEntries(fresh, constants.IMPORT)
+	exitOnError(err)
+
+	log.Printf("%s %d entries.  Use 'tt undo' to undo the import.\n", color.GreenString("Imported"), count)
+}
@@ -0,0 +1,468 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// The fields a CSV column can be mapped to, along with the headers each is
+// found under when it is not mapped.
+var csvFields = map[string][]string{
+	"date":    {"date", "day"},
+	"time":    {"time"},
+	"start":   {"start", "from"},
+	"end":     {"end", "to", "stop"},
+	"project": {"project"},
+	"tasks":   {"tasks", "task"},
+	"note":    {"note", "notes", "description"},
+	"url":     {"url", "link"},
+}
+
+// The date layouts that are tried, in order, when detecting the format of a
+// column.  Month first layouts come before day first ones unless --day-first
+// is given.
+var csvDateLayouts = []string{"2006-1-2", "2006/1/2", "1/2/2006", "2/1/2006", "1/2/06", "2/1/06", "2.1.2006", "2.1.06", "Jan 2, 2006", "2 Jan 2006"}
+var csvTimeLayouts = []string{"15:04", "15:04:05", "3:04 PM", "3:04PM", "3:04:05 PM", "3:04 pm", "3:04pm", "3:04:05 pm"}
+var csvDatetimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+// A csvRow is a row of the CSV file, with its line number, mapped to fields.
+type csvRow struct {
+	Line   int
+	Fields map[string]string
+}
+
+// A csvProblem is why the row on the line cannot be imported.
+type csvProblem struct {
+	Line    int
+	Message string
+}
+
+// A csvImport is what was read from a CSV file: the rows as entries, or as
+// intervals when they are spans of work, the problems found in them, and the
+// table of the rows that can be imported, shown by --dry-run.
+type csvImport struct {
+	IntervalMode bool
+	Entries      []models.Entry
+	Intervals    []interval
+	Problems     []csvProblem
+	Table        table.Writer
+}
+
+// importCsvCmd represents the import csv command.
+var importCsvCmd = &cobra.Command{
+	Use:   "csv <file>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Import a CSV file",
+	Long: `Import a CSV file, e.g. hours kept in a spreadsheet.  Columns are found by
+their header, e.g. 'Date', 'Time', 'Project', 'Tasks', 'Note', and 'URL', or
+mapped with --columns, e.g. --columns project=Client,tasks=Activity, by header
+or by number, starting at 1.  Each row is either when the work ended, in the
+time column, or a span of work, in the start and end columns, which can be
+just times when there is a date column.  The format of each date/time column is
+detected from its values.  Each row's project and task(s) must be valid for
+'tt add', either in the project and tasks columns, with multiple tasks
+separated by '+', or together as 'project+task' in the project column.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runImportCsv(cmd, args)
+	},
+}
+
+func init() {
+	importCsvCmd.Flags().StringToStringP(constants.COLUMNS, constants.EMPTY, map[string]string{}, "Map fields to columns, by header or number, e.g. date=Day,time=3,project=Client,tasks=Activity.")
+	importCsvCmd.Flags().StringP(constants.DELIMITER, constants.EMPTY, ",", "The character separating the columns, e.g. ';'.")
+	importCsvCmd.Flags().BoolP(constants.NO_HEADER, constants.EMPTY, false, "The file has no header row, so every column must be mapped by number.")
+	importCsvCmd.Flags().BoolP(constants.DAY_FIRST, constants.EMPTY, false, "Read ambiguous dates, e.g. 03/04/2024, as day first, i.e. 3 April.")
+	importCmd.AddCommand(importCsvCmd)
+}
+
+func runImportCsv(cmd *cobra.Command, args []string) {
+	dryRun, _ := cmd.Flags().GetBool(constants.DRY_RUN)
+
+	imported, err := readCsvImport(cmd, args[0])
+	exitOnError(err)
+
+	if dryRun && imported.Table.Length() > 0 {
+		log.Println(imported.Table.Render())
+	}
+
+	if len(imported.Problems) > 0 {
+		for _, p := range imported.Problems {
+			log.Printf("%s: Line %d: %s\n", color.RedString("Error"), p.Line, p.Message)
+		}
+
+		if !dryRun {
+			log.Fatalf("%s: %d problems in [%s].  Nothing imported.\n", color.RedString(constants.FATAL_NORMAL_CASE), len(imported.Problems), args[0])
+		}
+	}
+
+	if imported.IntervalMode {
+		importIntervals(cmd, openStore(), args[0], imported.Intervals, nil)
+	} else {
+		importEntries(cmd, openStore(), args[0], imported.Entries, nil)
+	}
+}
+
+// Read the CSV file's rows as entries, or as intervals, with the problems
+// found in them sorted by line.
+func readCsvImport(cmd *cobra.Command, filename string) (csvImport, error) {
+	dayFirst, _ := cmd.Flags().GetBool(constants.DAY_FIRST)
+
+	rows, err := readCsv(cmd, filename)
+	if err != nil {
+		return csvImport{}, err
+	}
+
+	var intervalMode bool = hasField(rows, "start") && hasField(rows, "end") && !hasField(rows, "time")
+
+	var entries []models.Entry
+	var intervals []interval
+	var problems []csvProblem
+	var t table.Writer = table.NewWriter()
+	if intervalMode {
+		var starts map[int]time.Time = parseCsvDatetimes(rows, "start", dayFirst, &problems)
+		var ends map[int]time.Time = parseCsvDatetimes(rows, "end", dayFirst, &problems)
+		t.AppendHeader(table.Row{"Line", "Start", "End", constants.PROJECT_NORMAL_CASE, constants.TASKS_NORMAL_CASE, constants.NOTE_NORMAL_CASE, constants.URL_NORMAL_CASE})
+		for _, row := range rows {
+			start, startOk := starts[row.Line]
+			end, endOk := ends[row.Line]
+			entry, ok := csvEntry(row, time.Time{}, &problems)
+			if !ok || !startOk || !endOk {
+				continue
+			}
+
+			if strings.EqualFold(entry.Project, constants.BREAK) || strings.EqualFold(entry.Project, constants.HELLO) {
+				problems = append(problems, csvProblem{row.Line, fmt.Sprintf("a span of work cannot be a %s.", entry.Project)})
+				continue
+			}
+
+			if !end.After(start) {
+				problems = append(problems, csvProblem{row.Line, fmt.Sprintf("end[%s] is not after start[%s].", row.Fields["end"], row.Fields["start"])})
+				continue
+			}
+
+			intervals = append(intervals, interval{Start: start, End: end, Project: entry.Project, Tasks: entryTasks(entry), Note: entry.Note, Url: entry.GetUrlAsString()})
+			t.AppendRow(table.Row{row.Line, start.Format(time.DateTime), end.Format(time.DateTime), entry.Project, entry.GetTasksAsString(), entry.Note, entry.GetUrlAsString()})
+		}
+	} else {
+		var field string = "time"
+		if !hasField(rows, field) {
+			field = "end"
+		}
+
+		if !hasField(rows, field) {
+			field = "date"
+		}
+
+		var ends map[int]time.Time = parseCsvDatetimes(rows, field, dayFirst, &problems)
+		t.AppendHeader(table.Row{"Line", constants.DATE_TIME_NORMAL_CASE, constants.PROJECT_NORMAL_CASE, constants.TASKS_NORMAL_CASE, constants.NOTE_NORMAL_CASE, constants.URL_NORMAL_CASE})
+		for _, row := range rows {
+			end, endOk := ends[row.Line]
+			entry, ok := csvEntry(row, end, &problems)
+			if !ok || !endOk {
+				continue
+			}
+
+			entries = append(entries, entry)
+			t.AppendRow(table.Row{row.Line, end.Format(time.DateTime), entry.Project, entry.GetTasksAsString(), entry.Note, entry.GetUrlAsString()})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return csvImport{IntervalMode: intervalMode, Entries: entries, Intervals: intervals, Problems: problems, Table: t}, nil
+}
+
+// Read the CSV file's rows, mapping their columns to fields.
+func readCsv(cmd *cobra.Command, filename string) ([]csvRow, error) {
+	columns, _ := cmd.Flags().GetStringToString(constants.COLUMNS)
+	delimiter, _ := cmd.Flags().GetString(constants.DELIMITER)
+	noHeader, _ := cmd.Flags().GetBool(constants.NO_HEADER)
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var reader *csv.Reader = csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if delimiter == `\t` {
+		delimiter = "\t"
+	}
+
+	if len([]rune(delimiter)) != 1 {
+		return nil, fmt.Errorf("invalid delimiter[%s], it must be a single character", delimiter)
+	}
+
+	reader.Comma = []rune(delimiter)[0]
+
+	var header []string
+	if !noHeader {
+		header, err = reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the header of [%s]. %w", filename, err)
+		}
+	}
+
+	indexes, err := csvColumnIndexes(header, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []csvRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		var row csvRow = csvRow{Line: line, Fields: make(map[string]string)}
+		var blank bool = true
+		for field, index := range indexes {
+			if index < len(record) {
+				row.Fields[field] = strings.TrimSpace(record[index])
+				blank = blank && row.Fields[field] == constants.EMPTY
+			}
+		}
+
+		if !blank {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+// Work out which column each field is in, from the mapping given with
+// --columns and, for the fields not mapped, from the header.
+func csvColumnIndexes(header []string, columns map[string]string) (map[string]int, error) {
+	var indexes map[string]int = make(map[string]int)
+	for field, column := range columns {
+		field = strings.ToLower(field)
+		if _, found := csvFields[field]; !found {
+			return nil, fmt.Errorf("unknown field[%s] in --columns, expected one of date, time, start, end, project, tasks, note, or url", field)
+		}
+
+		if number, err := strconv.Atoi(column); err == nil && number > 0 {
+			indexes[field] = number - 1
+			continue
+		}
+
+		var index int = findHeader(header, column)
+		if index < 0 {
+			return nil, fmt.Errorf("there is no column[%s] for field[%s]", column, field)
+		}
+
+		indexes[field] = index
+	}
+
+	for field, names := range csvFields {
+		if _, found := indexes[field]; found {
+			continue
+		}
+
+		for _, name := range names {
+			if index := findHeader(header, name); index >= 0 {
+				indexes[field] = index
+				break
+			}
+		}
+	}
+
+	if _, found := indexes["project"]; !found {
+		return nil, fmt.Errorf("there is no project column, use --columns project=<column> to map one")
+	}
+
+	_, hasDate := indexes["date"]
+	_, hasTime := indexes["time"]
+	_, hasEnd := indexes["end"]
+	if !hasDate && !hasTime && !hasEnd {
+		return nil, fmt.Errorf("there is no date, time, or end column, use --columns to map one")
+	}
+
+	return indexes, nil
+}
+
+func findHeader(header []string, name string) int {
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func hasField(rows []csvRow, field string) bool {
+	for _, row := range rows {
+		if _, found := row.Fields[field]; found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parse the date/times in the field, prefixed with the date field unless the
+// field is the date field, detecting their format from all of the values.
+// Returns the date/times by line number, adding a problem for each value that
+// cannot be parsed.
+func parseCsvDatetimes(rows []csvRow, field string, dayFirst bool, problems *[]csvProblem) map[int]time.Time {
+	var values map[int]string = make(map[int]string)
+	for _, row := range rows {
+		var value string = row.Fields[field]
+		if date, found := row.Fields["date"]; found && field != "date" && value != constants.EMPTY {
+			value = date + " " + value
+		}
+
+		values[row.Line] = value
+	}
+
+	var layout string = detectLayout(values, csvLayouts(dayFirst))
+
+	var datetimes map[int]time.Time = make(map[int]time.Time)
+	for _, row := range rows {
+		t, err := time.ParseInLocation(layout, values[row.Line], time.Local)
+		if err != nil || layout == constants.EMPTY {
+			var message string = fmt.Sprintf("unable to parse %s[%s].", field, values[row.Line])
+
+			// A date alone does not say when the work ended.
+			if field == "date" && detectLayout(map[int]string{row.Line: values[row.Line]}, csvDateLayouts) != constants.EMPTY {
+				message = fmt.Sprintf("date[%s] has no time, map the column with when the work ended, e.g. --columns time=<column>.", values[row.Line])
+			}
+
+			*problems = append(*problems, csvProblem{row.Line, message})
+			continue
+		}
+
+		datetimes[row.Line] = t
+	}
+
+	return datetimes
+}
+
+// The date/time layouts to try, in order.
+func csvLayouts(dayFirst bool) []string {
+	var dates []string = append([]string{}, csvDateLayouts...)
+	if dayFirst {
+		for i := 0; i+1 < len(dates); i++ {
+			if strings.HasPrefix(dates[i], "1/2/") && strings.HasPrefix(dates[i+1], "2/1/") {
+				dates[i], dates[i+1] = dates[i+1], dates[i]
+			}
+		}
+	}
+
+	var layouts []string = append([]string{}, csvDatetimeLayouts...)
+	for _, d := range dates {
+		for _, t := range csvTimeLayouts {
+			layouts = append(layouts, d+" "+t)
+		}
+	}
+
+	return layouts
+}
+
+// Pick the layout that parses the most values, preferring the earlier layout
+// when two parse as many.  Returns an empty layout when none parse any.
+func detectLayout(values map[int]string, layouts []string) string {
+	var best string = constants.EMPTY
+	var bestCount int = 0
+	for _, layout := range layouts {
+		var count int = 0
+		for _, value := range values {
+			if _, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+				count++
+			}
+		}
+
+		if count > bestCount {
+			best = layout
+			bestCount = count
+		}
+	}
+
+	return best
+}
+
+// Split "project+task", or "project+task+task" for more than one task, into
+// the project and its tasks.  Returns false if the project or a task is
+// missing, which 'tt add' lets through but a spreadsheet row most likely got
+// wrong.
+func splitProjectTask(projectTask string) (string, []string, bool) {
+	var pieces []string = strings.Split(projectTask, constants.TASK_DELIMITER)
+	if len(pieces) < 2 || strings.TrimSpace(pieces[0]) == constants.EMPTY {
+		return constants.EMPTY, nil, false
+	}
+
+	for _, task := range pieces[1:] {
+		if strings.TrimSpace(task) == constants.EMPTY {
+			return constants.EMPTY, nil, false
+		}
+	}
+
+	return pieces[0], pieces[1:], true
+}
+
+// Build the row's entry, at the specified date/time, validating its project
+// and task(s) the same way 'tt add' does.
+func csvEntry(row csvRow, datetime time.Time, problems *[]csvProblem) (models.Entry, bool) {
+	var project string = row.Fields["project"]
+	var projectTask string = project
+	if tasks := row.Fields["tasks"]; tasks != constants.EMPTY {
+		projectTask = project + constants.TASK_DELIMITER + tasks
+	}
+
+	var entry models.Entry
+	if strings.EqualFold(project, constants.BREAK) || strings.EqualFold(project, constants.HELLO) {
+		// Like 'tt break' and 'tt hello', these have no tasks.
+		entry = models.NewEntry(constants.UNKNOWN_UID, strings.ToLower(project), row.Fields["note"], datetime.Format(time.RFC3339))
+	} else {
+		p, tasks, ok := splitProjectTask(projectTask)
+		if !ok {
+			*problems = append(*problems, csvProblem{row.Line, fmt.Sprintf("malformed project+task[%s].", projectTask)})
+			return models.Entry{}, false
+		}
+
+		entry = models.NewEntry(constants.UNKNOWN_UID, strings.TrimSpace(p), row.Fields["note"], datetime.Format(time.RFC3339))
+		for _, task := range tasks {
+			entry.AddEntryProperty(constants.TASK, strings.TrimSpace(task))
+		}
+	}
+
+	if url := row.Fields["url"]; url != constants.EMPTY {
+		entry.AddEntryProperty(constants.URL, url)
+	}
+
+	return entry, true
+}
+
+// The entry's tasks.
+func entryTasks(e models.Entry) []string {
+	var tasks []string
+	for _, p := range e.Properties {
+		if p.Name == constants.TASK {
+			tasks = append(tasks, p.Value)
+		}
+	}
+
+	return tasks
+}
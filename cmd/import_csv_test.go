@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"timetracker/constants"
+)
+
+func TestReadCsvImport(t *testing.T) {
+	var tests = []struct {
+		name  string
+		csv   string
+		flags map[string]string
+		// The entries read, or imported into an empty store when the rows
+		// are spans of work, and the lines with problems.
+		want     []string
+		problems []int
+	}{
+		{
+			name: "a date and time column",
+			csv: `Date,Time,Project,Tasks,Note,URL
+2024-01-05,08:00,***hello,,,
+2024-01-05,09:30,acme,code review,"customer's ""bug"", fixed",https://example.com/pr/1
+2024-01-05,10:00,***Break,,lunch,
+2024-01-05,11:00,acme,calls+email,,
+`,
+			want: []string{
+				"2024-01-05 08:00 ***hello",
+				`2024-01-05 09:30 acme+code review (customer's "bug", fixed) <https://example.com/pr/1>`,
+				"2024-01-05 10:00 ***break (lunch)",
+				"2024-01-05 11:00 acme+calls+email",
+			},
+		},
+		{
+			name: "project+task in the project column and twelve hour times",
+			csv: `date,time,project
+1/5/2024,9:30 AM,acme+code review
+1/5/2024,1:15 PM,globex+calls+email
+`,
+			want: []string{"2024-01-05 09:30 acme+code review", "2024-01-05 13:15 globex+calls+email"},
+		},
+		{
+			name:  "ambiguous dates read day first",
+			csv:   "date,time,project\n03/04/2024,09:00,acme+calls\n",
+			flags: map[string]string{constants.DAY_FIRST: "true"},
+			want:  []string{"2024-04-03 09:00 acme+calls"},
+		},
+		{
+			name: "ambiguous dates read month first",
+			csv:  "date,time,project\n03/04/2024,09:00,acme+calls\n",
+			want: []string{"2024-03-04 09:00 acme+calls"},
+		},
+		{
+			name:  "columns mapped by header and number",
+			csv:   "Client;Activity;When\nacme;calls;2024-01-05T09:00:00\n",
+			flags: map[string]string{constants.DELIMITER: ";", constants.COLUMNS: "project=Client,tasks=2,end=3"},
+			want:  []string{"2024-01-05 09:00 acme+calls"},
+		},
+		{
+			name:  "no header",
+			csv:   "2024-01-05 09:00\tacme+calls\n\n2024-01-05 10:00\tacme+email\n",
+			flags: map[string]string{constants.DELIMITER: `\t`, constants.NO_HEADER: "true", constants.COLUMNS: "end=1,project=2"},
+			want:  []string{"2024-01-05 09:00 acme+calls", "2024-01-05 10:00 acme+email"},
+		},
+		{
+			name: "spans of work",
+			csv: `date,start,end,project,tasks
+2024-01-05,09:00,10:30,acme,code review
+2024-01-05,10:30,11:00,acme,calls
+2024-01-05,13:00,13:45,globex,email
+`,
+			want: []string{
+				"2024-01-05 09:00 ***hello",
+				"2024-01-05 10:30 acme+code review",
+				"2024-01-05 11:00 acme+calls",
+				"2024-01-05 13:00 ***break",
+				"2024-01-05 13:45 globex+email",
+			},
+		},
+		{
+			name:     "a column with more than one format",
+			csv:      "date,time,project\n2024-01-05,09:00,acme+calls\n2024-01-05,10:00:30,acme+email\n2024-01-05,11:00,acme+calls\n",
+			want:     []string{"2024-01-05 09:00 acme+calls", "2024-01-05 11:00 acme+calls"},
+			problems: []int{3},
+		},
+		{
+			name: "rows with problems",
+			csv: `date,time,project,tasks
+2024-01-05,09:00,acme,calls
+2024-01-05,09:30,acme,
+2024-01-05,soon,acme,calls
+2024-01-05,10:00,+calls,
+2024-01-05,10:30,acme,calls+
+`,
+			want:     []string{"2024-01-05 09:00 acme+calls"},
+			problems: []int{3, 4, 5, 6},
+		},
+		{
+			name: "spans of work with problems",
+			csv: `start,end,project
+2024-01-05 09:00,2024-01-05 10:00,acme+calls
+2024-01-05 11:00,2024-01-05 10:00,acme+calls
+2024-01-05 11:00,2024-01-05 12:00,***break
+`,
+			want:     []string{"2024-01-05 09:00 ***hello", "2024-01-05 10:00 acme+calls"},
+			problems: []int{3, 4},
+		},
+		{
+			name:     "a date without a time",
+			csv:      "date,project\n2024-01-05,acme+calls\n",
+			want:     []string{},
+			problems: []int{2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setFlags(t, importCsvCmd, test.flags)
+
+			var filename string = filepath.Join(t.TempDir(), "hours.csv")
+			if err := os.WriteFile(filename, []byte(test.csv), 0600); err != nil {
+				t.Fatalf("WriteFile() failed: %v", err)
+			}
+
+			imported, err := readCsvImport(importCsvCmd, filename)
+			if err != nil {
+				t.Fatalf("readCsvImport() failed: %v", err)
+			}
+
+			var lines []int = []int{}
+			for _, p := range imported.Problems {
+				lines = append(lines, p.Line)
+			}
+
+			if !slices.Equal(lines, append([]int{}, test.problems...)) {
+				t.Errorf("the problems are %v, want them on lines %v", imported.Problems, test.problems)
+			}
+
+			// The dry run lists each row that can be imported.
+			if imported.Table.Length() != len(imported.Entries)+len(imported.Intervals) {
+				t.Errorf("the dry run lists %d rows, want %d", imported.Table.Length(), len(imported.Entries)+len(imported.Intervals))
+			}
+
+			if imported.IntervalMode {
+				checkLines(t, "entries", importIntoEmptyStore(t, importCsvCmd, imported.Intervals), test.want)
+			} else {
+				checkLines(t, "entries", describeEntries(imported.Entries), test.want)
+			}
+		})
+	}
+}
+
+func TestReadCsvImportFails(t *testing.T) {
+	var tests = []struct {
+		name  string
+		csv   string
+		flags map[string]string
+	}{
+		{"no project column", "date,time,client\n2024-01-05,09:00,acme\n", nil},
+		{"no date, time, or end column", "project,tasks\nacme,calls\n", nil},
+		{"an unknown field", "date,time,project\n", map[string]string{constants.COLUMNS: "client=project"}},
+		{"a missing column", "date,time,project\n", map[string]string{constants.COLUMNS: "tasks=Activity"}},
+		{"a delimiter that is not a single character", "date;time;project\n", map[string]string{constants.DELIMITER: ";;"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setFlags(t, importCsvCmd, test.flags)
+
+			var filename string = filepath.Join(t.TempDir(), "hours.csv")
+			if err := os.WriteFile(filename, []byte(test.csv), 0600); err != nil {
+				t.Fatalf("WriteFile() failed: %v", err)
+			}
+
+			if _, err := readCsvImport(importCsvCmd, filename); err == nil {
+				t.Errorf("readCsvImport() succeeded")
+			}
+		})
+	}
+}
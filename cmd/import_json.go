@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/spf13/cobra"
+)
+
+// importJsonCmd represents the import json command.
+var importJsonCmd = &cobra.Command{
+	Use:   "json <archive>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Import a JSON archive written by 'tt export json'",
+	Long: `Import a JSON archive written by 'tt export json'.  Each entry keeps its uid,
+unless another entry already has it, along with its date/time and all of its
+properties.  Entries already in the database are skipped, so an archive can be
+imported more than once.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runImportJson(cmd, args)
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importJsonCmd)
+}
+
+func runImportJson(cmd *cobra.Command, args []string) {
+	entries, warnings, err := readArchive(args[0])
+	exitOnError(err)
+
+	importEntries(cmd, openStore(), args[0], entries, warnings)
+}
+
+// Read the entries in the archive, along with a warning for each entry that
+// could not be read.
+func readArchive(filename string) ([]models.Entry, []string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var a archive
+	err = json.Unmarshal(data, &a)
+	if err != nil || a.Format != archiveFormat {
+		return nil, nil, fmt.Errorf("[%s] is not a Time Tracker archive", filename)
+	}
+
+	if a.Version > archiveVersion {
+		return nil, nil, fmt.Errorf("[%s] is a version %d archive, but this version of Time Tracker only reads up to version %d", filename, a.Version, archiveVersion)
+	}
+
+	var entries []models.Entry
+	var warnings []string
+	for i, e := range a.Entries {
+		if _, err := time.Parse(time.RFC3339, e.EntryDatetime); err != nil || e.Project == constants.EMPTY {
+			warnings = append(warnings, fmt.Sprintf("Entry %d, uid %d, has no project or an invalid date/time[%s], skipped.", i+1, e.Uid, e.EntryDatetime))
+			continue
+		}
+
+		var entry models.Entry = models.NewEntry(e.Uid, e.Project, e.Note, e.EntryDatetime)
+		for _, p := range e.Properties {
+			entry.Properties = append(entry.Properties, models.NewProperty(e.Uid, p.Name, p.Value))
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, warnings, nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"timetracker/constants"
+
+	"github.com/spf13/cobra"
+)
+
+// An Org-mode headline, e.g. "** TODO code review :work:", without its TODO
+// keyword and tags.
+var orgHeadlinePattern = regexp.MustCompile(`^(\*+)\s+(?:(?:TODO|DONE)\s+)?(.*?)(?:\s+:[^\s]+:)?\s*$`)
+
+// A CLOCK line, e.g. "CLOCK: [2024-01-05 Fri 09:00]--[2024-01-05 Fri 10:30] =>  1:30",
+// or one that is still running, e.g. "CLOCK: [2024-01-05 Fri 09:00]".
+var orgClockPattern = regexp.MustCompile(`^\s*CLOCK:\s*\[([^\]]+)\](?:--\[([^\]]+)\])?`)
+
+// The date and time of a timestamp, skipping the day name, which is in the
+// language Emacs was using.
+var orgTimestampPattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:\s+[^\s\d]+)?\s+(\d{1,2}:\d{2})`)
+
+// The note after a CLOCK line, e.g. "- looked at PR".
+var orgNotePattern = regexp.MustCompile(`^\s*-\s+(.+)$`)
+
+// importOrgCmd represents the import org command.
+var importOrgCmd = &cobra.Command{
+	Use:   "org <file>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Import Emacs Org-mode CLOCK entries",
+	Long: `Import the CLOCK lines in an Emacs Org-mode file, e.g. one written by
+'tt export org'.  The top level headline a CLOCK line is under is the project
+and the headlines below it are the tasks, e.g. a CLOCK line under '* acme' and
+'** code review' becomes project 'acme' and task 'code review'.  A headline
+such as 'calls+email' is two tasks.  CLOCK lines directly under the project's
+headline have no task, the same way 'tt export org' writes them.  A note,
+'- note', right after a CLOCK line becomes the entry's note.  A ***hello is
+added at the start of each day's first CLOCK and a ***break at the start of
+any CLOCK that does not start where the one before it ended.  CLOCK lines that
+are still running, and CLOCK lines that overlap existing entries, are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runImportOrg(cmd, args)
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importOrgCmd)
+}
+
+func runImportOrg(cmd *cobra.Command, args []string) {
+	intervals, warnings, err := readOrg(args[0])
+	exitOnError(err)
+
+	importIntervals(cmd, openStore(), args[0], intervals, warnings)
+}
+
+// Read the CLOCK lines in the Org-mode file as intervals, along with a warning
+// for each CLOCK line that could not be read.
+func readOrg(filename string) ([]interval, []string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer file.Close()
+
+	var intervals []interval
+	var warnings []string
+	var path []string
+	var clocked bool = false
+	var scanner *bufio.Scanner = bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		var line string = scanner.Text()
+
+		if match := orgHeadlinePattern.FindStringSubmatch(line); match != nil {
+			var level int = len(match[1])
+			if level-1 < len(path) {
+				path = path[:level-1]
+			}
+
+			path = append(path, strings.TrimSpace(match[2]))
+			clocked = false
+			continue
+		}
+
+		// A note belongs to the CLOCK line right before it.
+		if match := orgNotePattern.FindStringSubmatch(line); match != nil && clocked {
+			intervals[len(intervals)-1].Note = strings.TrimSpace(match[1])
+			clocked = false
+			continue
+		}
+
+		clocked = false
+		var match []string = orgClockPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		if len(path) == 0 {
+			warnings = append(warnings, fmt.Sprintf("Line %d is a CLOCK line outside of any headline, skipped.", number))
+			continue
+		}
+
+		if match[2] == constants.EMPTY {
+			warnings = append(warnings, fmt.Sprintf("Line %d is a CLOCK line that is still running, skipped.", number))
+			continue
+		}
+
+		start, startErr := parseOrgTimestamp(match[1])
+		end, endErr := parseOrgTimestamp(match[2])
+		if startErr != nil || endErr != nil || !end.After(start) {
+			warnings = append(warnings, fmt.Sprintf("Line %d has an invalid CLOCK[%s--%s], skipped.", number, match[1], match[2]))
+			continue
+		}
+
+		var iv interval = interval{Start: start, End: end, Project: path[0]}
+		for _, headline := range path[1:] {
+			for _, task := range strings.Split(headline, constants.TASK_DELIMITER) {
+				if strings.TrimSpace(task) != constants.EMPTY {
+					iv.Tasks = append(iv.Tasks, strings.TrimSpace(task))
+				}
+			}
+		}
+
+		intervals = append(intervals, iv)
+		clocked = true
+	}
+
+	return intervals, warnings, scanner.Err()
+}
+
+// Parse an Org-mode timestamp, e.g. "2024-01-05 Fri 09:00", in the local time
+// zone.
+func parseOrgTimestamp(timestamp string) (time.Time, error) {
+	var match []string = orgTimestampPattern.FindStringSubmatch(strings.TrimSpace(timestamp))
+	if match == nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp[%s]", timestamp)
+	}
+
+	return time.ParseInLocation("2006-01-02 15:04", match[1]+" "+match[2], time.Local)
+}
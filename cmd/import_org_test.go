@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadOrg(t *testing.T) {
+	var tests = []struct {
+		name     string
+		org      string
+		want     []string
+		warnings int
+	}{
+		{
+			name: "keywords, tags, and notes",
+			org: `#+TITLE: Work
+* TODO acme :work:
+** DONE code review :review:urgent:
+:LOGBOOK:
+CLOCK: [2024-01-05 Fri 09:00]--[2024-01-05 Fri 10:30] =>  1:30
+-   looked at PR
+CLOCK: [2024-01-05 Fri 10:30]--[2024-01-05 Fri 11:00] =>  0:30
+:END:
+Some text that is not a note.
+- a list item that is not a note either
+** calls+email
+CLOCK: [2024-01-05 Fri 13:00]--[2024-01-05 Fri 13:30] =>  0:30
+* globex
+CLOCK: [2024-01-05 Fri 13:30]--[2024-01-05 Fri 14:00] =>  0:30
+`,
+			want: []string{
+				"2024-01-05 09:00 ***hello",
+				"2024-01-05 10:30 acme+code review (looked at PR)",
+				"2024-01-05 11:00 acme+code review",
+				"2024-01-05 13:00 ***break",
+				"2024-01-05 13:30 acme+calls+email",
+				"2024-01-05 14:00 globex",
+			},
+		},
+		{
+			name: "deeper headlines are more tasks",
+			org: `* acme
+** release
+*** testing
+CLOCK: [2024-01-05 Fri 09:00]--[2024-01-05 Fri 10:00]
+** planning
+CLOCK: [2024-01-05 Fri 10:00]--[2024-01-05 Fri 11:00]
+`,
+			want: []string{
+				"2024-01-05 09:00 ***hello",
+				"2024-01-05 10:00 acme+release+testing",
+				"2024-01-05 11:00 acme+planning",
+			},
+		},
+		{
+			name: "day names in another language and without them",
+			org: `* acme
+CLOCK: [2024-01-05 ven. 09:00]--[2024-01-05 10:00] =>  1:00
+`,
+			want: []string{"2024-01-05 09:00 ***hello", "2024-01-05 10:00 acme"},
+		},
+		{
+			name: "CLOCK lines that cannot be imported",
+			org: `CLOCK: [2024-01-05 Fri 08:00]--[2024-01-05 Fri 09:00] =>  1:00
+* acme
+CLOCK: [2024-01-05 Fri 09:00]
+CLOCK: [2024-01-05 Fri 10:00]--[2024-01-05 Fri 09:30] => -0:30
+CLOCK: [someday]--[2024-01-05 Fri 10:00]
+CLOCK: [2024-01-05 Fri 10:00]--[2024-01-05 Fri 11:00] =>  1:00
+`,
+			want:     []string{"2024-01-05 10:00 ***hello", "2024-01-05 11:00 acme"},
+			warnings: 4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var filename string = filepath.Join(t.TempDir(), "time.org")
+			if err := os.WriteFile(filename, []byte(test.org), 0600); err != nil {
+				t.Fatalf("WriteFile() failed: %v", err)
+			}
+
+			intervals, warnings, err := readOrg(filename)
+			if err != nil {
+				t.Fatalf("readOrg() failed: %v", err)
+			}
+
+			if len(warnings) != test.warnings {
+				t.Errorf("got warnings %q, want %d", warnings, test.warnings)
+			}
+
+			checkLines(t, "entries", importIntoEmptyStore(t, importOrgCmd, intervals), test.want)
+		})
+	}
+}
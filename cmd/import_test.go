@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+)
+
+// Describe the entry in one line, e.g. "2024-01-05 09:00 acme+code review
+// (note) <url>", with its date/time in the local time zone.
+func describeEntry(e models.Entry) string {
+	var description string = carbon.Parse(e.EntryDatetime).Format("Y-m-d H:i") + " " + strings.Join(append([]string{e.Project}, appendTasks([]string{}, e)...), constants.TASK_DELIMITER)
+	if e.Note != constants.EMPTY {
+		description += " (" + e.Note + ")"
+	}
+
+	if url := e.GetUrlAsString(); url != constants.EMPTY {
+		description += " <" + url + ">"
+	}
+
+	return description
+}
+
+// Describe each of the entries.
+func describeEntries(entries []models.Entry) []string {
+	var descriptions []string = []string{}
+	for _, e := range entries {
+		descriptions = append(descriptions, describeEntry(e))
+	}
+
+	return descriptions
+}
+
+// Import the intervals into an empty store, the way the command does, and
+// describe the entries that end up in it.
+func importIntoEmptyStore(t *testing.T, cmd *cobra.Command, intervals []interval) []string {
+	t.Helper()
+
+	var store *database.MemoryStore = database.NewMemoryStore()
+	importIntervals(cmd, store, "test", intervals, nil)
+
+	entries, err := store.GetEntriesMatching(database.EntryFilter{})
+	if err != nil {
+		t.Fatalf("GetEntriesMatching() failed: %v", err)
+	}
+
+	return describeEntries(entries)
+}
+
+// Fail the test unless the lines are the ones wanted, in order.
+func checkLines(t *testing.T, what string, got []string, want []string) {
+	t.Helper()
+
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("the %s are\n  %s\nwant\n  %s", what, strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+}
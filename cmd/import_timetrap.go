@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"timetracker/constants"
+
+	"github.com/spf13/cobra"
+)
+
+// The layouts timetrap has stored its timestamps in, in the local time zone
+// unless they have an offset.
+var timetrapLayouts = []string{
+	"2006-01-02 15:04:05.999999-0700",
+	"2006-01-02 15:04:05.999999-07:00",
+	"2006-01-02 15:04:05.999999",
+}
+
+// importTimetrapCmd represents the import timetrap command.
+var importTimetrapCmd = &cobra.Command{
+	Use:   "timetrap <sqlite>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Import a timetrap database",
+	Long: `Import a timetrap database, usually '~/.timetrap.db'.  Each timetrap entry is
+a span of work, so it becomes an entry at its end, for the project named after
+its sheet and with its note as the task.  Entries without a sheet are imported
+into the --project project.  A ***hello is added at the start of each day's
+first entry and a ***break at the start of any entry that does not start where
+the one before it ended.  Entries still running, and entries that overlap
+existing entries, are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runImportTimetrap(cmd, args)
+	},
+}
+
+func init() {
+	importTimetrapCmd.Flags().StringP(constants.PROJECT, constants.EMPTY, "general", "The project for entries without a sheet.")
+	importCmd.AddCommand(importTimetrapCmd)
+}
+
+func runImportTimetrap(cmd *cobra.Command, args []string) {
+	project, _ := cmd.Flags().GetString(constants.PROJECT)
+
+	intervals, warnings, err := readTimetrap(args[0], project)
+	exitOnError(err)
+
+	importIntervals(cmd, openStore(), args[0], intervals, warnings)
+}
+
+// Read the entries from the timetrap database as intervals, along with a
+// warning for each entry that could not be read.  Entries without a sheet
+// belong to the project.
+func readTimetrap(filename string, project string) ([]interval, []string, error) {
+	// Opening a database that does not exist would create it.
+	_, err := os.Stat(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := sql.Open("sqlite3", "file:"+filename+"?mode=ro")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer conn.Close()
+
+	// Cast the timestamps so the driver hands them back exactly as stored.
+	results, err := conn.Query("SELECT id, COALESCE(note, ''), CAST(start AS TEXT), CAST(\"end\" AS TEXT), COALESCE(sheet, '') FROM entries ORDER BY start;")
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s] is not a timetrap database. %w", filename, err)
+	}
+
+	defer results.Close()
+
+	var intervals []interval
+	var warnings []string
+	for results.Next() {
+		var id int64
+		var note, start, sheet string
+		var end sql.NullString
+		err = results.Scan(&id, &note, &start, &end, &sheet)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !end.Valid {
+			warnings = append(warnings, fmt.Sprintf("Timetrap entry %d is still running, skipped.", id))
+			continue
+		}
+
+		startTime, startErr := parseTimetrapTime(start)
+		endTime, endErr := parseTimetrapTime(end.String)
+		if startErr != nil || endErr != nil {
+			warnings = append(warnings, fmt.Sprintf("Timetrap entry %d has an invalid start[%s] or end[%s], skipped.", id, start, end.String))
+			continue
+		}
+
+		// Archived sheets are prefixed with an underscore.
+		var sheetProject string = strings.TrimSpace(strings.TrimPrefix(sheet, "_"))
+		if sheetProject == constants.EMPTY {
+			sheetProject = project
+		}
+
+		var task string = strings.TrimSpace(note)
+		if task == constants.EMPTY {
+			task = sheetProject
+		}
+
+		intervals = append(intervals, interval{Start: startTime, End: endTime, Project: sheetProject, Tasks: []string{task}})
+	}
+
+	return intervals, warnings, results.Err()
+}
+
+func parseTimetrapTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range timetrapLayouts {
+		var t time.Time
+		t, err = time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// Write a timetrap database with the entries, each one its note, start, end,
+// and sheet, where a nil end is still running.
+func writeTimetrap(t *testing.T, entries [][]any) string {
+	t.Helper()
+
+	var filename string = filepath.Join(t.TempDir(), "timetrap.db")
+	conn, err := sql.Open("sqlite3", filename)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+
+	defer conn.Close()
+
+	if _, err := conn.Exec(`CREATE TABLE entries (id INTEGER PRIMARY KEY, note VARCHAR(255), start TIMESTAMP, "end" TIMESTAMP, sheet VARCHAR(255));`); err != nil {
+		t.Fatalf("CREATE TABLE failed: %v", err)
+	}
+
+	for _, e := range entries {
+		if _, err := conn.Exec(`INSERT INTO entries (note, start, "end", sheet) VALUES (?, ?, ?, ?);`, e...); err != nil {
+			t.Fatalf("INSERT failed: %v", err)
+		}
+	}
+
+	return filename
+}
+
+func TestReadTimetrap(t *testing.T) {
+	var tests = []struct {
+		name    string
+		entries [][]any
+		// The entries imported into an empty store.
+		want     []string
+		warnings int
+	}{
+		{
+			name: "a day with a gap",
+			entries: [][]any{
+				{"code review", "2024-01-05 09:00:00.000000", "2024-01-05 10:30:00.000000", "acme"},
+				{"calls", "2024-01-05 10:30:00.000000", "2024-01-05 11:00:00.000000", "acme"},
+				{"email", "2024-01-05 13:00:00.000000", "2024-01-05 13:45:30.500000", "globex"},
+			},
+			want: []string{
+				"2024-01-05 09:00 ***hello",
+				"2024-01-05 10:30 acme+code review",
+				"2024-01-05 11:00 acme+calls",
+				"2024-01-05 13:00 ***break",
+				"2024-01-05 13:45 globex+email",
+			},
+		},
+		{
+			name: "archived sheets and notes",
+			entries: [][]any{
+				{nil, "2024-01-05 09:00:00", "2024-01-05 10:00:00", "_acme"},
+				{"  ", "2024-01-05 10:00:00", "2024-01-05 11:00:00", "globex"},
+			},
+			want: []string{
+				"2024-01-05 09:00 ***hello",
+				"2024-01-05 10:00 acme+acme",
+				"2024-01-05 11:00 globex+globex",
+			},
+		},
+		{
+			name: "entries without a sheet",
+			entries: [][]any{
+				{"planning", "2024-01-05 09:00:00", "2024-01-05 10:00:00", ""},
+				{nil, "2024-01-05 10:00:00", "2024-01-05 11:00:00", nil},
+			},
+			want: []string{
+				"2024-01-05 09:00 ***hello",
+				"2024-01-05 10:00 general+planning",
+				"2024-01-05 11:00 general+general",
+			},
+		},
+		{
+			name: "entries still running or with invalid times",
+			entries: [][]any{
+				{"calls", "2024-01-05 09:00:00", "2024-01-05 10:00:00", "acme"},
+				{"email", "2024-01-05 10:00:00", nil, "acme"},
+				{"email", "yesterday", "2024-01-05 11:00:00", "acme"},
+			},
+			want: []string{
+				"2024-01-05 09:00 ***hello",
+				"2024-01-05 10:00 acme+calls",
+			},
+			warnings: 2,
+		},
+		{
+			name:    "no entries",
+			entries: [][]any{},
+			want:    []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			intervals, warnings, err := readTimetrap(writeTimetrap(t, test.entries), "general")
+			if err != nil {
+				t.Fatalf("readTimetrap() failed: %v", err)
+			}
+
+			if len(warnings) != test.warnings {
+				t.Errorf("got warnings %q, want %d", warnings, test.warnings)
+			}
+
+			checkLines(t, "entries", importIntoEmptyStore(t, importTimetrapCmd, intervals), test.want)
+		})
+	}
+}
+
+func TestReadTimetrapIsNotATimetrapDatabase(t *testing.T) {
+	if _, _, err := readTimetrap(filepath.Join(t.TempDir(), "missing.db"), "general"); err == nil {
+		t.Errorf("readTimetrap() of a missing file succeeded")
+	}
+
+	var filename string = newTestDatabase(t).Filename
+	if _, _, err := readTimetrap(filename, "general"); err == nil {
+		t.Errorf("readTimetrap() of a Time Tracker database succeeded")
+	}
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"timetracker/constants"
+
+	"github.com/spf13/cobra"
+)
+
+// Timewarrior keeps a data file per month, e.g. "2024-01.data", next to files
+// like "tags.data" that are not intervals.
+var timewarriorFilePattern = regexp.MustCompile(`^\d{4}-\d{2}\.data$`)
+
+// The layout of Timewarrior's UTC timestamps, e.g. "20240105T090000Z".
+const timewarriorLayout string = "20060102T150405Z"
+
+// Tags with this prefix, e.g. "project:acme", name the project.
+const timewarriorProjectTag string = "project:"
+
+// A token on a Timewarrior data file line, remembering if it was quoted so a
+// quoted "#" tag is not mistaken for a separator.
+type timewarriorToken struct {
+	Text   string
+	Quoted bool
+}
+
+// importTimewarriorCmd represents the import timewarrior command.
+var importTimewarriorCmd = &cobra.Command{
+	Use:   "timewarrior <file|directory>...",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Import Timewarrior data files",
+	Long: `Import Timewarrior data files, e.g. '~/.timewarrior/data/2024-01.data', or
+every monthly data file in a directory, e.g. '~/.timewarrior/data'.  Each
+interval becomes an entry at its end, with its tags as the tasks and its
+annotation as the note.  A 'project:<name>' tag names the project, otherwise
+the --project project is used.  A ***hello is added at the start of each
+day's first interval and a ***break at the start of any interval that does not
+start where the one before it ended.  Open intervals, and intervals that
+overlap existing entries, are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runImportTimewarrior(cmd, args)
+	},
+}
+
+func init() {
+	importTimewarriorCmd.Flags().StringP(constants.PROJECT, constants.EMPTY, "general", "The project for intervals without a 'project:<name>' tag.")
+	importCmd.AddCommand(importTimewarriorCmd)
+}
+
+func runImportTimewarrior(cmd *cobra.Command, args []string) {
+	project, _ := cmd.Flags().GetString(constants.PROJECT)
+
+	var filenames []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		exitOnError(err)
+
+		if !info.IsDir() {
+			filenames = append(filenames, arg)
+			continue
+		}
+
+		files, err := os.ReadDir(arg)
+		exitOnError(err)
+
+		for _, f := range files {
+			if !f.IsDir() && timewarriorFilePattern.MatchString(f.Name()) {
+				filenames = append(filenames, filepath.Join(arg, f.Name()))
+			}
+		}
+	}
+
+	var intervals []interval
+	var warnings []string
+	for _, filename := range filenames {
+		read, problems, err := readTimewarrior(filename, project)
+		exitOnError(err)
+
+		intervals = append(intervals, read...)
+		warnings = append(warnings, problems...)
+	}
+
+	importIntervals(cmd, openStore(), strings.Join(args, ", "), intervals, warnings)
+}
+
+// Read the intervals in the Timewarrior data file, along with a warning for
+// each line that could not be read.
+func readTimewarrior(filename string, project string) ([]interval, []string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer file.Close()
+
+	var intervals []interval
+	var warnings []string
+	var scanner *bufio.Scanner = bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		var line string = strings.TrimSpace(scanner.Text())
+		if line == constants.EMPTY {
+			continue
+		}
+
+		iv, err := parseTimewarriorLine(line, project)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s line %d: %s, skipped.", filename, number, err.Error()))
+			continue
+		}
+
+		intervals = append(intervals, iv)
+	}
+
+	return intervals, warnings, scanner.Err()
+}
+
+// Parse a line such as 'inc 20240105T090000Z - 20240105T103000Z # acme
+// "code review" # "annotation"'.
+func parseTimewarriorLine(line string, project string) (interval, error) {
+	var tokens []timewarriorToken = tokenizeTimewarrior(line)
+	if len(tokens) < 2 || tokens[0].Text != "inc" {
+		return interval{}, fmt.Errorf("not a Timewarrior interval[%s]", line)
+	}
+
+	if len(tokens) < 4 || tokens[2].Text != "-" {
+		return interval{}, fmt.Errorf("interval starting %s is still open", tokens[1].Text)
+	}
+
+	start, err := time.Parse(timewarriorLayout, tokens[1].Text)
+	if err != nil {
+		return interval{}, fmt.Errorf("invalid start[%s]", tokens[1].Text)
+	}
+
+	end, err := time.Parse(timewarriorLayout, tokens[3].Text)
+	if err != nil {
+		return interval{}, fmt.Errorf("invalid end[%s]", tokens[3].Text)
+	}
+
+	var iv interval = interval{Start: start.Local(), End: end.Local(), Project: project}
+
+	// The tags come after the first "#" and the annotation after the second.
+	var section int = 0
+	var annotation []string
+	for _, token := range tokens[4:] {
+		if token.Text == "#" && !token.Quoted {
+			section++
+			continue
+		}
+
+		switch {
+		case section == 1 && strings.HasPrefix(token.Text, timewarriorProjectTag):
+			iv.Project = strings.TrimPrefix(token.Text, timewarriorProjectTag)
+		case section == 1:
+			iv.Tasks = append(iv.Tasks, token.Text)
+		case section >= 2:
+			annotation = append(annotation, token.Text)
+		}
+	}
+
+	iv.Note = strings.Join(annotation, " ")
+	if len(iv.Tasks) == 0 {
+		iv.Tasks = []string{iv.Project}
+	}
+
+	return iv, nil
+}
+
+// Split the line on spaces, keeping double quoted text, with its backslash
+// escapes removed, together.
+func tokenizeTimewarrior(line string) []timewarriorToken {
+	var tokens []timewarriorToken
+	var current timewarriorToken
+	var inToken, quoted, escaped bool
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.Text += string(r)
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			current.Quoted = true
+			inToken = true
+		case r == ' ' && !quoted:
+			if inToken {
+				tokens = append(tokens, current)
+				current = timewarriorToken{}
+				inToken = false
+			}
+		default:
+			current.Text += string(r)
+			inToken = true
+		}
+	}
+
+	if inToken {
+		tokens = append(tokens, current)
+	}
+
+	return tokens
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadTimewarrior(t *testing.T) {
+	// Timewarrior stores UTC timestamps, which are imported in the local time
+	// zone.
+	var at = func(timestamp string) string {
+		utc, err := time.Parse(timewarriorLayout, timestamp)
+		if err != nil {
+			t.Fatalf("Parse() failed: %v", err)
+		}
+
+		return utc.Local().Format("2006-01-02 15:04")
+	}
+
+	var tests = []struct {
+		name     string
+		data     string
+		want     []string
+		warnings int
+	}{
+		{
+			name: "tags and annotations",
+			data: `inc 20240105T120000Z - 20240105T130000Z # acme "code review" # "looked at # PR"
+inc 20240105T130000Z - 20240105T133000Z # project:globex calls email
+inc 20240105T140000Z - 20240105T150000Z
+`,
+			want: []string{
+				at("20240105T120000Z") + " ***hello",
+				at("20240105T130000Z") + " general+acme+code review (looked at # PR)",
+				at("20240105T133000Z") + " globex+calls+email",
+				at("20240105T140000Z") + " ***break",
+				at("20240105T150000Z") + " general+general",
+			},
+		},
+		{
+			name: "quoted and escaped tags",
+			data: `inc 20240105T120000Z - 20240105T130000Z # "#1" "say \"hi\"" "project:big client"` + "\n",
+			want: []string{
+				at("20240105T120000Z") + " ***hello",
+				at("20240105T130000Z") + " big client+#1+say \"hi\"",
+			},
+		},
+		{
+			name: "open intervals and lines that are not intervals",
+			data: `inc 20240105T120000Z - 20240105T130000Z # acme
+inc 20240105T130000Z # acme
+inc 2024-01-05 - 20240105T140000Z # acme
+exc 20240105T140000Z - 20240105T150000Z
+
+inc 20240105T150000Z - 2024-01-05 # acme
+`,
+			want: []string{
+				at("20240105T120000Z") + " ***hello",
+				at("20240105T130000Z") + " general+acme",
+			},
+			warnings: 4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var filename string = filepath.Join(t.TempDir(), "2024-01.data")
+			if err := os.WriteFile(filename, []byte(test.data), 0600); err != nil {
+				t.Fatalf("WriteFile() failed: %v", err)
+			}
+
+			intervals, warnings, err := readTimewarrior(filename, "general")
+			if err != nil {
+				t.Fatalf("readTimewarrior() failed: %v", err)
+			}
+
+			if len(warnings) != test.warnings {
+				t.Errorf("got warnings %q, want %d", warnings, test.warnings)
+			}
+
+			checkLines(t, "entries", importIntoEmptyStore(t, importTimewarriorCmd, intervals), test.want)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+)
+
+// A utt log line, e.g. "2024-01-05 09:00 project: task", optionally with the
+// offset it was recorded in, e.g. "2024-01-05 09:00+0100 hello".
+var uttLinePattern = regexp.MustCompile(`^(\d{4}-\d{1,2}-\d{1,2} \d{1,2}:\d{2})([+-]\d{2}:?\d{2})?\s+(.+)$`)
+
+// importUttCmd represents the import utt command.
+var importUttCmd = &cobra.Command{
+	Use:   "utt <file>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Import a utt (Ultimate Time Tracker) log file",
+	Long: `Import a utt (Ultimate Time Tracker) log file, usually '~/.local/share/utt/utt.log'.
+Like Time Tracker, utt records when each activity ended.  'hello' becomes a
+***hello, activities ending in '**', or '***', become a ***break with the
+activity as its note, and 'project: task' becomes that project and task.
+Activities without a project are imported into the --project project.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runImportUtt(cmd, args)
+	},
+}
+
+func init() {
+	importUttCmd.Flags().StringP(constants.PROJECT, constants.EMPTY, "general", "The project for activities without one.")
+	importCmd.AddCommand(importUttCmd)
+}
+
+func runImportUtt(cmd *cobra.Command, args []string) {
+	project, _ := cmd.Flags().GetString(constants.PROJECT)
+
+	file, err := os.Open(args[0])
+	exitOnError(err)
+	defer file.Close()
+
+	entries, warnings, err := parseUtt(file, project)
+	exitOnError(err)
+
+	importEntries(cmd, openStore(), args[0], entries, warnings)
+}
+
+// Parse the utt log, returning its entries along with a warning for each line
+// that could not be parsed.
+func parseUtt(reader io.Reader, project string) ([]models.Entry, []string, error) {
+	var entries []models.Entry
+	var warnings []string
+	var scanner *bufio.Scanner = bufio.NewScanner(reader)
+	for number := 1; scanner.Scan(); number++ {
+		var line string = strings.TrimSpace(scanner.Text())
+		if line == constants.EMPTY {
+			continue
+		}
+
+		var match []string = uttLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			warnings = append(warnings, fmt.Sprintf("Line %d[%s] is not a utt log line, skipped.", number, line))
+			continue
+		}
+
+		datetime, err := parseUttDatetime(match[1], match[2])
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("Line %d has an invalid date/time[%s%s], skipped.", number, match[1], match[2]))
+			continue
+		}
+
+		entries = append(entries, uttEntry(strings.TrimSpace(match[3]), datetime, project))
+	}
+
+	return entries, warnings, scanner.Err()
+}
+
+// Parse a utt date/time in the local time zone, unless it has an offset.
+func parseUttDatetime(datetime string, offset string) (carbon.Carbon, error) {
+	var t time.Time
+	var err error
+	if offset == constants.EMPTY {
+		t, err = time.ParseInLocation("2006-1-2 15:04", datetime, time.Local)
+	} else {
+		t, err = time.Parse("2006-1-2 15:04-0700", datetime+strings.Replace(offset, ":", constants.EMPTY, 1))
+	}
+
+	if err != nil {
+		return carbon.Carbon{}, err
+	}
+
+	return carbon.CreateFromStdTime(t), nil
+}
+
+// Convert a utt activity into an entry.
+func uttEntry(activity string, datetime carbon.Carbon, project string) models.Entry {
+	if strings.EqualFold(activity, "hello") {
+		return models.NewEntry(constants.UNKNOWN_UID, constants.HELLO, constants.EMPTY, datetime.ToRfc3339String())
+	}
+
+	if strings.Contains(activity, "**") {
+		var note string = strings.TrimSpace(strings.Trim(activity, "* "))
+		if strings.EqualFold(note, "break") {
+			note = constants.EMPTY
+		}
+
+		return models.NewEntry(constants.UNKNOWN_UID, constants.BREAK, note, datetime.ToRfc3339String())
+	}
+
+	var task string = activity
+	if p, t, found := strings.Cut(activity, ":"); found && strings.TrimSpace(t) != constants.EMPTY {
+		project = strings.TrimSpace(p)
+		task = strings.TrimSpace(t)
+	}
+
+	var entry models.Entry = models.NewEntry(constants.UNKNOWN_UID, project, constants.EMPTY, datetime.ToRfc3339String())
+	entry.AddEntryProperty(constants.TASK, task)
+	return entry
+}
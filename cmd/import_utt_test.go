@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseUtt(t *testing.T) {
+	var tests = []struct {
+		name     string
+		log      string
+		want     []string
+		warnings int
+	}{
+		{
+			name: "a day",
+			log: `2024-01-05 08:00 hello
+2024-01-05 09:30 acme: code review
+2024-01-05 10:00 lunch **
+2024-01-05 11:00 email
+2024-01-05 11:15 break ***
+`,
+			want: []string{
+				"2024-01-05 08:00 ***hello",
+				"2024-01-05 09:30 acme+code review",
+				"2024-01-05 10:00 ***break (lunch)",
+				"2024-01-05 11:00 general+email",
+				"2024-01-05 11:15 ***break",
+			},
+		},
+		{
+			name: "single digit months, days, and hours",
+			log:  "2024-1-5 9:05 acme: calls\n",
+			want: []string{"2024-01-05 09:05 acme+calls"},
+		},
+		{
+			name: "a colon without a task",
+			log:  "2024-01-05 09:00 meeting: \n",
+			want: []string{"2024-01-05 09:00 general+meeting:"},
+		},
+		{
+			name:     "lines that are not utt log lines",
+			log:      "# comment\n\n2024-01-05 acme: calls\n2024-13-45 09:00 acme: calls\n2024-01-05 09:00 acme: calls\n",
+			want:     []string{"2024-01-05 09:00 acme+calls"},
+			warnings: 3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, warnings, err := parseUtt(strings.NewReader(test.log), "general")
+			if err != nil {
+				t.Fatalf("parseUtt() failed: %v", err)
+			}
+
+			checkLines(t, "entries", describeEntries(entries), test.want)
+			if len(warnings) != test.warnings {
+				t.Errorf("got warnings %q, want %d", warnings, test.warnings)
+			}
+		})
+	}
+}
+
+func TestParseUttWithOffset(t *testing.T) {
+	entries, _, err := parseUtt(strings.NewReader("2024-01-05 09:00+0100 hello\n2024-01-05 09:00-05:30 acme: calls\n"), "general")
+	if err != nil {
+		t.Fatalf("parseUtt() failed: %v", err)
+	}
+
+	var want = []string{"2024-01-05T09:00:00+01:00", "2024-01-05T09:00:00-05:30"}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+
+	for i, e := range entries {
+		if e.EntryDatetime != want[i] {
+			t.Errorf("entry %d is at [%s], want [%s]", i, e.EntryDatetime, want[i])
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// importWatsonCmd represents the import watson command.
+var importWatsonCmd = &cobra.Command{
+	Use:   "watson <frames>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Import a Watson frames file",
+	Long: `Import a Watson frames file, usually '~/.config/watson/frames'.  Each frame
+becomes an entry at its end, for its project, with its tags as the tasks.  A
+***hello is added at the start of each day's first frame and a ***break at the
+start of any frame that does not start where the one before it ended.  Frames
+that overlap existing entries are skipped.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runImportWatson(cmd, args)
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importWatsonCmd)
+}
+
+func runImportWatson(cmd *cobra.Command, args []string) {
+	intervals, warnings, err := readWatson(args[0])
+	exitOnError(err)
+
+	importIntervals(cmd, openStore(), args[0], intervals, warnings)
+}
+
+// Read the frames in the Watson frames file as intervals, along with a warning
+// for each frame that could not be read.  Each frame is an array of its start,
+// stop, project, id, tags, and when it was last updated, followed by its note
+// in newer versions of Watson.
+func readWatson(filename string) ([]interval, []string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var frames [][]any
+	err = json.Unmarshal(data, &frames)
+	if err != nil {
+		return nil, nil, fmt.Errorf("[%s] is not a Watson frames file. %w", filename, err)
+	}
+
+	var intervals []interval
+	var warnings []string
+	for i, frame := range frames {
+		iv, ok := parseWatsonFrame(frame)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("Frame %d%v is not a Watson frame, skipped.", i+1, frame))
+			continue
+		}
+
+		intervals = append(intervals, iv)
+	}
+
+	return intervals, warnings, nil
+}
+
+func parseWatsonFrame(frame []any) (interval, bool) {
+	if len(frame) < 5 {
+		return interval{}, false
+	}
+
+	start, startOk := frame[0].(float64)
+	stop, stopOk := frame[1].(float64)
+	project, projectOk := frame[2].(string)
+	tags, tagsOk := frame[4].([]any)
+	if !startOk || !stopOk || !projectOk || !tagsOk || project == "" {
+		return interval{}, false
+	}
+
+	var iv interval = interval{Start: time.Unix(int64(start), 0), End: time.Unix(int64(stop), 0), Project: project}
+	for _, tag := range tags {
+		if s, ok := tag.(string); ok {
+			iv.Tasks = append(iv.Tasks, s)
+		}
+	}
+
+	if len(iv.Tasks) == 0 {
+		iv.Tasks = []string{project}
+	}
+
+	if len(frame) > 6 {
+		iv.Note, _ = frame[6].(string)
+	}
+
+	return iv, true
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Write a Watson frames file.
+func writeWatson(t *testing.T, frames string) string {
+	t.Helper()
+
+	var filename string = filepath.Join(t.TempDir(), "frames")
+	if err := os.WriteFile(filename, []byte(frames), 0600); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	return filename
+}
+
+func TestReadWatson(t *testing.T) {
+	// Watson stores Unix timestamps.
+	var unix = func(datetime string) int64 {
+		at, err := time.ParseInLocation("2006-01-02 15:04", datetime, time.Local)
+		if err != nil {
+			t.Fatalf("ParseInLocation() failed: %v", err)
+		}
+
+		return at.Unix()
+	}
+
+	var nine, ten, eleven, noon int64 = unix("2024-01-05 09:00"), unix("2024-01-05 10:00"), unix("2024-01-05 11:00"), unix("2024-01-05 12:00")
+
+	var tests = []struct {
+		name     string
+		frames   string
+		want     []string
+		warnings int
+	}{
+		{
+			name:   "frames without when they were updated",
+			frames: fmt.Sprintf(`[[%d, %d, "acme", "id1", ["code review"]]]`, nine, ten),
+			want:   []string{"2024-01-05 09:00 ***hello", "2024-01-05 10:00 acme+code review"},
+		},
+		{
+			name:   "frames without a note",
+			frames: fmt.Sprintf(`[[%d, %d, "acme", "id1", ["calls", "email"], %d]]`, nine, ten, ten),
+			want:   []string{"2024-01-05 09:00 ***hello", "2024-01-05 10:00 acme+calls+email"},
+		},
+		{
+			name:   "frames with a note",
+			frames: fmt.Sprintf(`[[%d, %d, "acme", "id1", [], %d, "looked at PR"], [%d, %d, "acme", "id2", ["calls"], %d, null]]`, nine, ten, ten, eleven, noon, noon),
+			want:   []string{"2024-01-05 09:00 ***hello", "2024-01-05 10:00 acme+acme (looked at PR)", "2024-01-05 11:00 ***break", "2024-01-05 12:00 acme+calls"},
+		},
+		{
+			name:   "tags that are not text",
+			frames: fmt.Sprintf(`[[%d, %d, "acme", "id1", [42, "calls", null], %d]]`, nine, ten, ten),
+			want:   []string{"2024-01-05 09:00 ***hello", "2024-01-05 10:00 acme+calls"},
+		},
+		{
+			name:     "frames that are not Watson frames",
+			frames:   fmt.Sprintf(`[[%d, %d, "acme", "id1"], [%d, %d, "", "id2", []], [%d, %d, "acme", "id3", "calls"], ["09:00", %d, "acme", "id4", []], [%d, %d, "acme", "id5", ["calls"]]]`, nine, ten, nine, ten, nine, ten, ten, ten, eleven),
+			want:     []string{"2024-01-05 10:00 ***hello", "2024-01-05 11:00 acme+calls"},
+			warnings: 4,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			intervals, warnings, err := readWatson(writeWatson(t, test.frames))
+			if err != nil {
+				t.Fatalf("readWatson() failed: %v", err)
+			}
+
+			if len(warnings) != test.warnings {
+				t.Errorf("got warnings %q, want %d", warnings, test.warnings)
+			}
+
+			checkLines(t, "entries", importIntoEmptyStore(t, importWatsonCmd, intervals), test.want)
+		})
+	}
+}
+
+func TestReadWatsonIsNotAFramesFile(t *testing.T) {
+	if _, _, err := readWatson(writeWatson(t, `{"frames": []}`)); err == nil {
+		t.Errorf("readWatson() of a file that is not a frames file succeeded")
+	}
+}
@@ -2,97 +2,176 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/csv"
 	"fmt"
 	"log"
 	"math"
 	"math/rand"
+	"os"
+	"strconv"
 	"time"
 	"timetracker/constants"
 	"timetracker/internal/database"
+	"timetracker/internal/models"
 
+	"github.com/agrison/go-commons-lang/stringUtils"
+	"github.com/fatih/color"
 	"github.com/golang-module/carbon/v2"
 	"github.com/inancgumus/screen"
+	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var nukeCmd = &cobra.Command{
 	Use:   "nuke",
 	Short: "Nukes entries from the sqlite database",
-	Long:  `As you continuously add completed entries, the database continues to go unbounded.  The nuke command allows you to manage the database size.`,
+	Long: `As you continuously add completed entries, the database continues to go unbounded.  The nuke command allows you to manage the database size.  Entries can be selected with --all, --prior-years, or any combination of --from, --to, --before, --project, and --task.  Nuked entries are moved to the trash; use 'tt trash empty' to permanently delete them.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runNuke(cmd, args)
+		runNuke(cmd, args, openStore())
 	},
 }
 
 func init() {
 	nukeCmd.Flags().BoolP(constants.ALL, constants.EMPTY, false, "Nuke ALL entries.  Use with extreme caution!!!")
 	nukeCmd.Flags().BoolP(constants.PRIOR_YEARS, constants.EMPTY, false, "Nuke all entries prior to the current year's entries.")
-	nukeCmd.Flags().BoolP(constants.DRY_RUN, constants.EMPTY, false, "Do not actually nuke anything, but show what potential would be nuked.")
+	nukeCmd.Flags().StringP(constants.FROM, constants.EMPTY, constants.EMPTY, "Nuke the entries on or after this date, in "+constants.DATE_FORMAT+" format.")
+	nukeCmd.Flags().StringP(constants.TO, constants.EMPTY, constants.EMPTY, "Nuke the entries on or before this date, in "+constants.DATE_FORMAT+" format.")
+	nukeCmd.Flags().StringP(constants.BEFORE, constants.EMPTY, constants.EMPTY, "Nuke the entries before this date, in "+constants.DATE_FORMAT+" format.")
+	nukeCmd.Flags().StringP(constants.PROJECT, constants.EMPTY, constants.EMPTY, "Nuke only the entries for this project.")
+	nukeCmd.Flags().StringP(constants.TASK, constants.EMPTY, constants.EMPTY, "Nuke only the entries with this task.")
+	nukeCmd.Flags().StringP(constants.EXPORT, constants.EMPTY, constants.EMPTY, "Export the matching entries to this CSV file before nuking them.")
+	nukeCmd.Flags().BoolP(constants.DRY_RUN, constants.EMPTY, false, "Do not actually nuke anything, but list the entries that would be nuked.")
 	rootCmd.AddCommand(nukeCmd)
 }
 
-func runNuke(cmd *cobra.Command, _ []string) {
+func runNuke(cmd *cobra.Command, _ []string, store database.Store) {
 	all, _ := cmd.Flags().GetBool(constants.ALL)
-	priorYears, _ := cmd.Flags().GetBool(constants.PRIOR_YEARS)
+	export, _ := cmd.Flags().GetString(constants.EXPORT)
 	dryRun, _ := cmd.Flags().GetBool(constants.DRY_RUN)
 
+	var filter database.EntryFilter = nukeFilter(cmd)
+	var filtered bool = filter != database.EntryFilter{}
+	if all && filtered {
+		log.Fatalf("%s: --%s cannot be combined with any other filter.\n", color.RedString(constants.FATAL_NORMAL_CASE), constants.ALL)
+	} else if !all && !filtered {
+		cmd.Help()
+		return
+	}
+
+	entries, err := store.GetEntriesMatching(filter)
+	exitOnError(err)
+
+	if len(entries) == 0 {
+		log.Printf("No entries match.  Nothing nuked.\n")
+		return
+	}
+
+	if dryRun {
+		log.Println(renderNukeEntries(entries))
+		log.Printf("%d entries would have been nuked.\n", len(entries))
+		return
+	}
+
+	var label string = fmt.Sprintf("Are you sure you want to nuke the %d matching entries from the database?", len(entries))
 	if all {
-		yesNo := yesNoPrompt("Are you sure you want to nuke ALL the entries from your database?")
-		if yesNo {
-			yesNo = yesNoPrompt("WARNING: Are you REALLY sure you want to nuke ALL the entries from your database?")
-			if yesNo {
-				yesNo = yesNoPrompt("LAST WARNING: Are you REALLY REALLY sure you want to nuke ALL the entries from your database?")
-				if yesNo {
-					// Yes was enter, so nuke ALL entries.
-					db := database.New(viper.GetString(constants.DATABASE_FILE))
-					var count = db.NukeAllEntries(dryRun)
-					showExplosion()
-					if dryRun {
-						log.Printf("All %d entries would have been nuked.", count)
-					} else {
-						log.Printf("All entries nuked.\n")
-					}
-				} else {
-					log.Printf("Nothing nuked.\n")
-				}
-			} else {
-				log.Printf("Nothing nuked.\n")
-			}
-		} else {
-			log.Printf("Nothing nuked.\n")
-		}
-	} else if priorYears {
-		var year int = carbon.Now().Year()
-		var prompt = fmt.Sprintf("Are you sure you want to nuke all entries prior to %d from the database?", year)
-		yesNo := yesNoPrompt(prompt)
-		if yesNo {
-			prompt = fmt.Sprintf("WARNING: Are you REALLY sure you want to nuke all entries prior to %d from the database?", year)
-			yesNo = yesNoPrompt(prompt)
-			if yesNo {
-				prompt = fmt.Sprintf("LAST WARNING: Are you REALLY REALLY sure you want to nuke all entries prior to %d from the database?", year)
-				yesNo = yesNoPrompt(prompt)
-				if yesNo {
-					db := database.New(viper.GetString(constants.DATABASE_FILE))
-					var count = db.NukePriorYearsEntries(dryRun, year)
-					showExplosion()
-					if dryRun {
-						log.Printf("All %d entries prior to %d would have been nuked.", count, year)
-					} else {
-						log.Printf("All entries prior to %d have been nuked.", year)
-					}
-				} else {
-					log.Printf("Nothing nuked.\n")
-				}
-			} else {
-				log.Printf("Nothing nuked.\n")
-			}
-		} else {
-			log.Printf("Nothing nuked.\n")
+		label = fmt.Sprintf("Are you sure you want to nuke ALL %d entries from the database?", len(entries))
+	}
+
+	if !yesNoPrompt(label) {
+		log.Printf("Nothing nuked.\n")
+		return
+	}
+
+	// Nothing is nuked unless the export succeeded.
+	if !stringUtils.IsEmpty(export) {
+		err = exportNukeEntries(export, entries)
+		if err != nil {
+			log.Fatalf("%s: Unable to export entries to [%s].  Nothing nuked.  %s\n", color.RedString(constants.FATAL_NORMAL_CASE), export, err.Error())
 		}
-	} else {
-		cmd.Help()
+
+		log.Printf("%s %d entries to [%s].\n", color.GreenString("Exported"), len(entries), export)
 	}
+
+	backupBeforeDestructive(store, constants.NUKE)
+
+	count, err := store.NukeEntries(filter)
+	exitOnError(err)
+	showExplosion()
+	log.Printf("%d entries nuked.  Use 'tt trash restore' to get them back.\n", count)
+}
+
+// Build the filter for the entries to nuke from the flags, other than --all.
+func nukeFilter(cmd *cobra.Command) database.EntryFilter {
+	priorYears, _ := cmd.Flags().GetBool(constants.PRIOR_YEARS)
+	fromStr, _ := cmd.Flags().GetString(constants.FROM)
+	toStr, _ := cmd.Flags().GetString(constants.TO)
+	beforeStr, _ := cmd.Flags().GetString(constants.BEFORE)
+	project, _ := cmd.Flags().GetString(constants.PROJECT)
+	task, _ := cmd.Flags().GetString(constants.TASK)
+
+	var filter database.EntryFilter = database.EntryFilter{Project: project, Task: task}
+	if !stringUtils.IsEmpty(fromStr) {
+		filter.From = parseDateFlag(fromStr).StartOfDay().ToIso8601String()
+	}
+
+	if !stringUtils.IsEmpty(toStr) {
+		filter.To = parseDateFlag(toStr).EndOfDay().ToIso8601String()
+	}
+
+	if !stringUtils.IsEmpty(beforeStr) {
+		filter.Before = parseDateFlag(beforeStr).StartOfDay().ToIso8601String()
+	}
+
+	// Prior years means strictly before the start of the current year, so
+	// entries dated in the future are left alone.
+	if priorYears {
+		filter.Before = carbon.Now().StartOfYear().ToIso8601String()
+	}
+
+	return filter
+}
+
+// Parse a date flag, exiting on an invalid date.
+func parseDateFlag(s string) carbon.Carbon {
+	var date carbon.Carbon = carbon.Parse(s)
+	if date.Error != nil {
+		log.Fatalf("%s: Invalid date[%s].  Please use the %s format.\n", color.RedString(constants.FATAL_NORMAL_CASE), s, constants.DATE_FORMAT)
+	}
+
+	return date
+}
+
+func renderNukeEntries(entries []models.Entry) string {
+	var t table.Writer = table.NewWriter()
+	t.AppendHeader(table.Row{"Uid", constants.DATE_TIME_NORMAL_CASE, constants.PROJECT_NORMAL_CASE, constants.TASKS_NORMAL_CASE, constants.NOTE_NORMAL_CASE})
+	for _, e := range entries {
+		t.AppendRow(table.Row{e.Uid, e.EntryDatetime, e.Project, e.GetTasksAsString(), e.Note})
+	}
+
+	return t.Render()
+}
+
+// Write the entries to filename as CSV, one row per entry.
+func exportNukeEntries(filename string, entries []models.Entry) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	var w *csv.Writer = csv.NewWriter(file)
+	w.Write([]string{"uid", constants.ENTRY_DATETIME, constants.PROJECT, "tasks", constants.NOTE, constants.URL})
+	for _, e := range entries {
+		w.Write([]string{strconv.FormatInt(e.Uid, 10), e.EntryDatetime, e.Project, e.GetTasksAsString(), e.Note, e.GetUrlAsString()})
+	}
+
+	w.Flush()
+	err = w.Error()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
 }
 
 // Show the nuclear explosion on the screen.
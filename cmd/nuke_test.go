@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+)
+
+// Set the command's flags for the rest of the test, putting them back to their
+// defaults afterwards.
+func setFlags(t *testing.T, cmd *cobra.Command, flags map[string]string) {
+	t.Helper()
+
+	for name, value := range flags {
+		if err := cmd.Flags().Set(name, value); err != nil {
+			t.Fatalf("Set(--%s=%s) failed: %v", name, value, err)
+		}
+	}
+
+	t.Cleanup(func() {
+		for name := range flags {
+			var f = cmd.Flags().Lookup(name)
+			f.Changed = false
+
+			// Setting a map flag adds to it, so it is replaced by a new, empty,
+			// one instead.
+			if f.Value.Type() == "stringToString" {
+				var empty *cobra.Command = &cobra.Command{}
+				empty.Flags().StringToString(name, map[string]string{}, f.Usage)
+				f.Value = empty.Flags().Lookup(name).Value
+				continue
+			}
+
+			f.Value.Set(f.DefValue)
+		}
+	})
+}
+
+// The uids of the entries.
+func entryUids(entries []models.Entry) []int64 {
+	var uids []int64 = []int64{}
+	for _, e := range entries {
+		uids = append(uids, e.Uid)
+	}
+
+	return uids
+}
+
+func TestNukeFilters(t *testing.T) {
+	// The entries are in the local time zone, the same one the flags' dates
+	// are in.
+	var lastYear string = carbon.Now().SubYear().StartOfYear().AddDays(10).ToDateString()
+	var entries = []models.Entry{
+		newTestEntry(1, "acme", constants.EMPTY, localDatetime(lastYear+" 09:00:00"), constants.TASK, "calls"),
+		newTestEntry(2, "acme", constants.EMPTY, localDatetime("2024-01-04 23:59:59"), constants.TASK, "code review"),
+		newTestEntry(3, "Globex", constants.EMPTY, localDatetime("2024-01-05 00:00:00"), constants.TASK, "Calls", constants.TASK, "email"),
+		newTestEntry(4, "acme", constants.EMPTY, localDatetime("2024-01-05 23:59:59")),
+		newTestEntry(5, "globex", constants.EMPTY, localDatetime("2024-01-06 00:00:00"), constants.TASK, "email"),
+		newTestEntry(6, "acme", constants.EMPTY, carbon.Now().ToRfc3339String(), constants.TASK, "calls"),
+	}
+
+	var tests = []struct {
+		name  string
+		flags map[string]string
+		// The uids of the entries nuked, in order.
+		want []int64
+	}{
+		{"project ignores case", map[string]string{constants.PROJECT: "GLOBEX"}, []int64{3, 5}},
+		{"task ignores case", map[string]string{constants.TASK: "calls"}, []int64{1, 3, 6}},
+		{"from includes the whole day", map[string]string{constants.FROM: "2024-01-05"}, []int64{1, 3, 4, 5, 6}},
+		{"to includes the whole day", map[string]string{constants.TO: "2024-01-05"}, []int64{2, 3, 4}},
+		{"before excludes the day", map[string]string{constants.BEFORE: "2024-01-05"}, []int64{2}},
+		{"from and to", map[string]string{constants.FROM: "2024-01-05", constants.TO: "2024-01-05"}, []int64{3, 4}},
+		{"project and task", map[string]string{constants.PROJECT: "acme", constants.TASK: "calls"}, []int64{1, 6}},
+		{"prior years", map[string]string{constants.PRIOR_YEARS: "true"}, []int64{1, 2, 3, 4, 5}},
+		{"nothing matches", map[string]string{constants.PROJECT: "initech"}, []int64{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setFlags(t, nukeCmd, test.flags)
+			var filter database.EntryFilter = nukeFilter(nukeCmd)
+
+			forEachStore(t, entries, func(t *testing.T, store database.Store) {
+				matching, err := store.GetEntriesMatching(filter)
+				if err != nil {
+					t.Fatalf("GetEntriesMatching() failed: %v", err)
+				}
+
+				var want []int64 = test.want
+				var got []int64 = entryUids(matching)
+				slices.Sort(got)
+				if !slices.Equal(got, want) {
+					t.Fatalf("%v matches %v, want %v", test.flags, got, want)
+				}
+
+				// The dry run lists each of the matching entries.
+				var rendered string = renderNukeEntries(matching)
+				for _, e := range matching {
+					if !strings.Contains(rendered, e.EntryDatetime) {
+						t.Errorf("the dry run does not list entry[%d]\n%s", e.Uid, rendered)
+					}
+				}
+
+				count, err := store.NukeEntries(filter)
+				if err != nil {
+					t.Fatalf("NukeEntries() failed: %v", err)
+				}
+
+				if count != int64(len(want)) {
+					t.Errorf("nuked %d entries, want %d", count, len(want))
+				}
+
+				remaining, err := store.GetEntriesMatching(database.EntryFilter{})
+				if err != nil {
+					t.Fatalf("GetEntriesMatching() failed: %v", err)
+				}
+
+				for _, e := range remaining {
+					if slices.Contains(want, e.Uid) {
+						t.Errorf("entry[%d] was not nuked", e.Uid)
+					}
+				}
+
+				if len(remaining)+len(want) != len(entries) {
+					t.Errorf("%d entries remain, want %d", len(remaining), len(entries)-len(want))
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"timetracker/constants"
+	"timetracker/internal/database"
+
+	"github.com/fatih/color"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// The name of the active profile, from --profile or the configuration file.
+var profileName string
+
+// Profile names double as part of the database filename, so keep them simple.
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// A Profile overrides some of the default configuration with its own
+// database, favorites, and rounding.
+type Profile struct {
+	DatabaseFilename string     `yaml:"database_file"`
+	RoundToMinutes   int        `yaml:"round_to_minutes"`
+	Favorites        []Favorite `yaml:"favorites"`
+}
+
+// profileCmd represents the profile command.
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "List, create, or switch between profiles",
+	Long: `A profile is a named set of settings, kept in the 'profiles' section of the
+configuration file, with its own database file, favorites, and
+round_to_minutes.  The settings at the top of the configuration file are the
+'default' profile.  Use --profile to run a single command against a profile,
+or 'tt profile use' to change the profile used when --profile is not given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// profileListCmd represents the profile list command.
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.ExactArgs(0),
+	Short: "List the profiles",
+	Long:  "List the profiles along with each one's database file.  The active profile is marked with a '*'.",
+	Run: func(cmd *cobra.Command, args []string) {
+		runProfileList(cmd, args)
+	},
+}
+
+// profileCreateCmd represents the profile create command.
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Create a profile",
+	Long: `Create a profile, and its database, in the configuration file.  Unless
+--database-file is given, the profile's database is '.timetracker-<name>.db' in
+your home directory.  Until favorites are added to the profile in the
+configuration file, the default profile's favorites are used.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runProfileCreate(cmd, args)
+	},
+}
+
+// profileUseCmd represents the profile use command.
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Change the active profile",
+	Long:  "Change the profile used when --profile is not given.  Use 'default' to go back to the default profile.",
+	Run: func(cmd *cobra.Command, args []string) {
+		runProfileUse(cmd, args)
+	},
+}
+
+func init() {
+	profileCreateCmd.Flags().StringP(constants.DATABASE_FILE_FLAG, constants.EMPTY, constants.EMPTY, "The profile's database file.")
+	profileCreateCmd.Flags().IntP(constants.ROUND_TO_MINUTES_FLAG, constants.EMPTY, 0, "Round the profile's reports to this many minutes, instead of the default profile's value.")
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	rootCmd.AddCommand(profileCmd)
+}
+
+func runProfileList(_ *cobra.Command, _ []string) {
+	var config Configuration = readConfiguration()
+
+	var names []string = make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var t table.Writer = table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.AppendHeader(table.Row{constants.EMPTY, "Profile", "Database", "Round To Minutes"})
+	t.AppendRow(table.Row{activeMarker(constants.DEFAULT_PROFILE), constants.DEFAULT_PROFILE, defaultDatabaseFilename(config), defaultRoundToMinutes(config)})
+	for _, name := range names {
+		var p Profile = config.Profiles[name]
+		var roundToMinutes int = p.RoundToMinutes
+		if roundToMinutes <= 0 {
+			roundToMinutes = defaultRoundToMinutes(config)
+		}
+
+		t.AppendRow(table.Row{activeMarker(name), name, profileDatabaseFilename(name, p), roundToMinutes})
+	}
+
+	log.Println(t.Render())
+}
+
+func runProfileCreate(cmd *cobra.Command, args []string) {
+	var name string = args[0]
+	databaseFile, _ := cmd.Flags().GetString(constants.DATABASE_FILE_FLAG)
+	roundToMinutes, _ := cmd.Flags().GetInt(constants.ROUND_TO_MINUTES_FLAG)
+
+	if !profileNamePattern.MatchString(name) || name == constants.DEFAULT_PROFILE {
+		log.Fatalf("%s: Invalid profile name[%s].  Please use lowercase letters, digits, '-', and '_'.\n", color.RedString(constants.FATAL_NORMAL_CASE), name)
+	}
+
+	var config Configuration = readConfiguration()
+	if _, found := config.Profiles[name]; found {
+		log.Fatalf("%s: Profile[%s] already exists.\n", color.RedString(constants.FATAL_NORMAL_CASE), name)
+	}
+
+	var p Profile = Profile{DatabaseFilename: databaseFile, RoundToMinutes: roundToMinutes}
+	if p.DatabaseFilename == constants.EMPTY {
+		p.DatabaseFilename = profileDatabaseFilename(name, p)
+	}
+
+	editConfiguration(func(root *yaml.Node) {
+		var profile *yaml.Node = mappingValue(mappingValue(root, constants.PROFILES, yaml.MappingNode), name, yaml.MappingNode)
+		mappingValue(profile, constants.DATABASE_FILE, yaml.ScalarNode).SetString(p.DatabaseFilename)
+		if p.RoundToMinutes > 0 {
+			var node *yaml.Node = mappingValue(profile, constants.ROUND_TO_MINUTES, yaml.ScalarNode)
+			node.SetString(fmt.Sprint(p.RoundToMinutes))
+			node.Tag = "!!int"
+		}
+	})
+
+	// Opening the database applies all the migrations, which creates it.
+	db, err := database.New(p.DatabaseFilename)
+	exitOnError(err)
+	db.Close()
+
+	log.Printf("%s profile[%s] with database[%s].  Use 'tt profile use %s' to make it the active profile.\n", color.GreenString("Created"), name, p.DatabaseFilename, name)
+}
+
+func runProfileUse(_ *cobra.Command, args []string) {
+	var name string = args[0]
+
+	var config Configuration = readConfiguration()
+	if _, found := config.Profiles[name]; !found && name != constants.DEFAULT_PROFILE {
+		log.Fatalf("%s: Profile[%s] does not exist.  Use 'tt profile create %s' to create it.\n", color.RedString(constants.FATAL_NORMAL_CASE), name, name)
+	}
+
+	editConfiguration(func(root *yaml.Node) {
+		mappingValue(root, constants.PROFILE, yaml.ScalarNode).SetString(name)
+	})
+
+	log.Printf("Now using profile[%s].\n", name)
+}
+
+// Apply the active profile's settings on top of the default profile's.  The
+// active profile comes from --profile, then the 'profile' setting, and falls
+// back to the default profile.
+func applyProfile(home string) {
+	if profileName == constants.EMPTY {
+		profileName = viper.GetString(constants.PROFILE)
+	}
+
+	if profileName == constants.EMPTY || profileName == constants.DEFAULT_PROFILE {
+		profileName = constants.DEFAULT_PROFILE
+		return
+	}
+
+	var key string = constants.PROFILES + "." + profileName
+	if !viper.IsSet(key) {
+		log.Fatalf("%s: Profile[%s] does not exist in configuration file[%s].  Use '--profile %s' or 'tt profile create %s'.\n", color.RedString(constants.FATAL_NORMAL_CASE), profileName, viper.ConfigFileUsed(), constants.DEFAULT_PROFILE, profileName)
+	}
+
+	var databaseFile string = viper.GetString(key + "." + constants.DATABASE_FILE)
+	if databaseFile == constants.EMPTY {
+		databaseFile = filepath.Join(home, ".timetracker-"+profileName+".db")
+	}
+
+	viper.Set(constants.DATABASE_FILE, databaseFile)
+
+	if viper.GetInt(key+"."+constants.ROUND_TO_MINUTES) > 0 {
+		viper.Set(constants.ROUND_TO_MINUTES, viper.GetInt(key+"."+constants.ROUND_TO_MINUTES))
+	}
+}
+
+// The active profile's favorites, falling back to the default profile's when
+// the active profile has none.
+func (c Configuration) activeFavorites() []Favorite {
+	p, found := c.Profiles[profileName]
+	if found && len(p.Favorites) > 0 {
+		return p.Favorites
+	}
+
+	return c.Favorites
+}
+
+func activeMarker(name string) string {
+	if name == profileName {
+		return "*"
+	}
+
+	return constants.EMPTY
+}
+
+func defaultDatabaseFilename(config Configuration) string {
+	if config.DatabaseFilename != constants.EMPTY {
+		return config.DatabaseFilename
+	}
+
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".timetracker.db")
+}
+
+func defaultRoundToMinutes(config Configuration) int {
+	if config.RoundToMinutes > 0 {
+		return config.RoundToMinutes
+	}
+
+	return 15
+}
+
+func profileDatabaseFilename(name string, p Profile) string {
+	if p.DatabaseFilename != constants.EMPTY {
+		return p.DatabaseFilename
+	}
+
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".timetracker-"+name+".db")
+}
+
+// Read the configuration file.
+func readConfiguration() Configuration {
+	data, err := os.ReadFile(viper.ConfigFileUsed())
+	if err != nil {
+		log.Fatalf("%s: Error reading configuration file[%s]. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), viper.ConfigFileUsed(), err.Error())
+	}
+
+	var config Configuration
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		log.Fatalf("%s: Error unmarshalling configuration file[%s]. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), viper.ConfigFileUsed(), err.Error())
+	}
+
+	return config
+}
+
+// Edit the configuration file in place, keeping its comments and the order of
+// its settings.
+func editConfiguration(edit func(root *yaml.Node)) {
+	var filename string = viper.ConfigFileUsed()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		log.Fatalf("%s: Error reading configuration file[%s]. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), filename, err.Error())
+	}
+
+	var document yaml.Node
+	err = yaml.Unmarshal(data, &document)
+	if err != nil {
+		log.Fatalf("%s: Error unmarshalling configuration file[%s]. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), filename, err.Error())
+	}
+
+	// An empty file has no document yet.
+	if len(document.Content) == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	edit(document.Content[0])
+
+	// Match the two space indentation viper writes.
+	var buffer bytes.Buffer
+	var encoder *yaml.Encoder = yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&document)
+	if err == nil {
+		err = os.WriteFile(filename, buffer.Bytes(), 0644)
+	}
+
+	if err != nil {
+		log.Fatalf("%s: Error writing configuration file[%s]. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), filename, err.Error())
+	}
+}
+
+// Get the value for key in the mapping, adding an empty value of the
+// specified kind if the key is not there yet.
+func mappingValue(mapping *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			// A key with nothing after it, e.g. "profiles:", is null.
+			var value *yaml.Node = mapping.Content[i+1]
+			if value.Kind != kind {
+				*value = yaml.Node{Kind: kind}
+			}
+
+			return value
+		}
+	}
+
+	var value *yaml.Node = &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"timetracker/constants"
+
+	"github.com/spf13/viper"
+)
+
+// Read the configuration file, the way the root command does, with the home
+// directory a temporary one.  The configuration and the active profile are
+// put back afterwards.
+func useConfiguration(t *testing.T, config string) string {
+	t.Helper()
+
+	var home string = t.TempDir()
+	t.Setenv("HOME", home)
+
+	var filename string = filepath.Join(home, ".timetracker.yaml")
+	if err := os.WriteFile(filename, []byte(config), 0644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+
+	viper.Reset()
+	viper.SetConfigFile(filename)
+	viper.SetDefault(constants.DATABASE_FILE, filepath.Join(home, ".timetracker.db"))
+	viper.SetDefault(constants.ROUND_TO_MINUTES, 15)
+	if err := viper.ReadInConfig(); err != nil {
+		t.Fatalf("ReadInConfig() failed: %v", err)
+	}
+
+	var previous string = profileName
+	t.Cleanup(func() {
+		viper.Reset()
+		profileName = previous
+	})
+
+	return home
+}
+
+func TestProfileSwitching(t *testing.T) {
+	const config string = `database_file: /data/default.db
+round_to_minutes: 15
+favorites:
+  - favorite: acme+calls
+profile: %s
+profiles:
+  work:
+    database_file: /data/work.db
+    round_to_minutes: 6
+    favorites:
+      - favorite: globex+email
+  side:
+    round_to_minutes: 0
+`
+
+	var tests = []struct {
+		name string
+		// The profile setting in the configuration file and the --profile flag.
+		active  string
+		flag    string
+		profile string
+		// The database file, relative to the home directory unless absolute.
+		database       string
+		roundToMinutes int64
+		favorite       string
+	}{
+		{"default", constants.EMPTY, constants.EMPTY, constants.DEFAULT_PROFILE, "/data/default.db", 15, "acme+calls"},
+		{"active profile", "work", constants.EMPTY, "work", "/data/work.db", 6, "globex+email"},
+		{"flag", constants.EMPTY, "work", "work", "/data/work.db", 6, "globex+email"},
+		{"flag overrides the active profile", "work", constants.DEFAULT_PROFILE, constants.DEFAULT_PROFILE, "/data/default.db", 15, "acme+calls"},
+		{"profile without its own settings", "side", constants.EMPTY, "side", ".timetracker-side.db", 15, "acme+calls"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var home string = useConfiguration(t, fmt.Sprintf(config, test.active))
+			profileName = test.flag
+			applyProfile(home)
+
+			var database string = test.database
+			if !filepath.IsAbs(database) {
+				database = filepath.Join(home, database)
+			}
+
+			if profileName != test.profile {
+				t.Errorf("the active profile is [%s], want [%s]", profileName, test.profile)
+			}
+
+			if got := viper.GetString(constants.DATABASE_FILE); got != database {
+				t.Errorf("the database file is [%s], want [%s]", got, database)
+			}
+
+			if got := viper.GetInt64(constants.ROUND_TO_MINUTES); got != test.roundToMinutes {
+				t.Errorf("round_to_minutes is %d, want %d", got, test.roundToMinutes)
+			}
+
+			var favorites []Favorite = readConfiguration().activeFavorites()
+			if len(favorites) != 1 || favorites[0].Favorite != test.favorite {
+				t.Errorf("the favorites are %v, want [%s]", favorites, test.favorite)
+			}
+		})
+	}
+}
+
+func TestProfileCreateAndUse(t *testing.T) {
+	var home string = useConfiguration(t, "# Kept across edits.\nround_to_minutes: 15\n")
+
+	var database string = filepath.Join(home, "client.db")
+	setFlags(t, profileCreateCmd, map[string]string{constants.DATABASE_FILE_FLAG: database, constants.ROUND_TO_MINUTES_FLAG: "10"})
+	runProfileCreate(profileCreateCmd, []string{"client"})
+	if _, err := os.Stat(database); err != nil {
+		t.Fatalf("the profile's database was not created: %v", err)
+	}
+
+	runProfileUse(profileUseCmd, []string{"client"})
+
+	data, err := os.ReadFile(viper.ConfigFileUsed())
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte("# Kept across edits.\n")) {
+		t.Errorf("the configuration file lost its comment\n%s", data)
+	}
+
+	// Read the edited configuration file again, the way the next command
+	// would.
+	useConfiguration(t, string(data))
+	profileName = constants.EMPTY
+	applyProfile(home)
+
+	if profileName != "client" {
+		t.Errorf("the active profile is [%s], want [client]", profileName)
+	}
+
+	if got := viper.GetString(constants.DATABASE_FILE); got != database {
+		t.Errorf("the database file is [%s], want [%s]", got, database)
+	}
+
+	if got := viper.GetInt64(constants.ROUND_TO_MINUTES); got != 10 {
+		t.Errorf("round_to_minutes is %d, want 10", got)
+	}
+}
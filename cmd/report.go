@@ -8,9 +8,11 @@ import (
 	"log"
 	"math"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"timetracker/constants"
 	"timetracker/internal/database"
 	"timetracker/internal/models"
@@ -20,7 +22,6 @@ import (
 	"github.com/agrison/go-commons-lang/stringUtils"
 	"github.com/fatih/color"
 	"github.com/golang-module/carbon/v2"
-	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -29,7 +30,6 @@ var from string
 var to string
 
 var daysOfWeek = map[string]string{}
-var roundToMinutes int64
 
 // reportCmd represents the report command.
 var reportCmd = &cobra.Command{
@@ -37,15 +37,16 @@ var reportCmd = &cobra.Command{
 	Short: "Generate a report",
 	Long:  `When you need to generate a report, default today, use this command.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		runReport(cmd, args)
+		runReport(cmd, args, openStore())
 	},
 }
 
 func dashes(input string) string {
+	// When the report is redirected to a file, there is no terminal to be as
+	// wide as.
 	width, _, err := term.GetSize(int(os.Stdout.Fd()))
 	if err != nil {
-		log.Fatalf("%s: Error getting terminal dimensions. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		width = 80
 	}
 
 	var pad string = strings.Repeat("-", (((width - 2) - len(input)) / 2))
@@ -65,6 +66,9 @@ func init() {
 	reportCmd.Flags().BoolP("last-entry", constants.EMPTY, false, "Display the last entry's information.")
 	reportCmd.Flags().StringVarP(&from, "from", constants.EMPTY, constants.EMPTY, "Specify an inclusive start date to report in "+constants.DATE_FORMAT+" format.")
 	reportCmd.Flags().StringVarP(&to, "to", constants.EMPTY, constants.EMPTY, "Specify an inclusive end date to report in "+constants.DATE_FORMAT+" format.  If this is a day of the week, then it is the next occurrence from the start date of the report, including the start date itself.")
+	reportCmd.Flags().BoolP(constants.INCLUDE_ARCHIVES, constants.EMPTY, false, "Also report on the entries in the archive databases.")
+	reportCmd.Flags().StringP(constants.FORMAT, constants.EMPTY, "table", "Render the report as a table, or as json, csv, or markdown.")
+	reportCmd.Flags().StringP(constants.TZ, constants.EMPTY, constants.EMPTY, "Report, and bucket days, in this IANA time zone, e.g. America/New_York, instead of the configured or local one.")
 	reportCmd.MarkFlagsRequiredTogether("from", "to")
 	rootCmd.AddCommand(reportCmd)
 
@@ -106,324 +110,256 @@ func plural(count int, singular string) (result string) {
 	return
 }
 
-func reportByDay(durations map[int64]models.UID, entries []models.Entry) {
-	var show_by_day_totals bool = viper.GetBool(constants.SHOW_BY_DAY_TOTALS)
-	log.Printf("\n")
-	log.Printf("%s\n", dashes(" By Day "))
-	log.Printf("\n")
+// A reportDuration is a duration in seconds, both rounded to round_to_minutes,
+// which is what the report shows, and as recorded.
+type reportDuration struct {
+	Seconds    int64 `json:"seconds"`
+	RawSeconds int64 `json:"raw_seconds"`
+}
 
-	// Consolidate by day.
-	var consolidatedByDay map[string]map[string]models.Entry = make(map[string]map[string]models.Entry)
-	for _, e := range entries {
-		if strings.EqualFold(e.Project, constants.HELLO) {
-			continue
-		}
+// Add the entry's duration, rounded and as recorded, to the duration.
+func (d *reportDuration) add(u models.UID) {
+	d.Seconds += u.RoundedDuration
+	d.RawSeconds += u.Duration
+}
 
-		var task = e.GetTasksAsString()
-		consolidatedDay, found := consolidatedByDay[carbon.Parse(e.EntryDatetime).Format(constants.CARBON_DATE_FORMAT)]
-		if found {
-			consolidatedProject, found := consolidatedDay[e.Project]
-			if found {
-				if len(task) > 0 {
-					consolidatedProject.AddEntryProperty(constants.TASK, task)
-				}
-
-				// Add the rounded durations together.
-				consolidatedProject.Duration += round(durations[e.Uid].Duration)
-
-				// Replace the consolidated entry.
-				consolidatedByDay[carbon.Parse(e.EntryDatetime).Format(constants.CARBON_DATE_FORMAT)][e.Project] = consolidatedProject
-			} else {
-				var entry models.Entry = models.NewEntry(e.Uid, e.Project, e.Note, e.EntryDatetime)
-				entry.Duration = round(durations[e.Uid].Duration)
-				if len(task) > 0 {
-					entry.AddEntryProperty(constants.TASK, task)
-				}
-
-				// Add the new entry.
-				consolidatedByDay[carbon.Parse(e.EntryDatetime).Format(constants.CARBON_DATE_FORMAT)][e.Project] = entry
-			}
-		} else {
-			// Since the EntryDatetime was not found, add it.
-			var entry models.Entry = models.NewEntry(e.Uid, e.Project, e.Note, e.EntryDatetime)
-			entry.Duration = round(durations[e.Uid].Duration)
-			if len(task) > 0 {
-				entry.AddEntryProperty(constants.TASK, task)
-			}
+// The total time worked and on break.
+type reportTotals struct {
+	Work  reportDuration `json:"work"`
+	Break reportDuration `json:"break"`
+	Total reportDuration `json:"total"`
+}
 
-			// Add the new entry.
-			var key string = carbon.Parse(entry.EntryDatetime).Format(constants.CARBON_DATE_FORMAT)
-			consolidatedByDay[key] = make(map[string]models.Entry)
-			consolidatedByDay[key][entry.Project] = entry
-		}
-	}
+type reportProject struct {
+	Project  string         `json:"project"`
+	Tasks    []string       `json:"tasks"`
+	Duration reportDuration `json:"duration"`
+}
 
-	// Since maps are not sorted in go... why, I have no idea, you need to first
-	// sort the keys and then access the map via those sorted keys.
-	var sortedKeys []string = make([]string, 0, len(consolidatedByDay))
-	for key := range consolidatedByDay {
-		sortedKeys = append(sortedKeys, key)
-	}
-	sort.SliceStable(sortedKeys, func(i, j int) bool { return sortedKeys[i] < sortedKeys[j] })
+type reportTask struct {
+	Tasks    string         `json:"tasks"`
+	Projects []string       `json:"projects"`
+	Url      string         `json:"url"`
+	Duration reportDuration `json:"duration"`
+}
 
-	// Create and configure the table.
-	var t table.Writer = table.NewWriter()
-	t.Style().Options.DrawBorder = false
-	t.AppendHeader(table.Row{constants.DATE_NORMAL_CASE, constants.PROJECT_NORMAL_CASE, constants.TASKS_NORMAL_CASE, constants.DURATION_NORMAL_CASE})
+type reportEntry struct {
+	Uid      int64          `json:"uid"`
+	Date     string         `json:"date"`
+	Start    string         `json:"start"`
+	End      string         `json:"end"`
+	Project  string         `json:"project"`
+	Tasks    []string       `json:"tasks"`
+	Note     string         `json:"note"`
+	Url      string         `json:"url"`
+	Duration reportDuration `json:"duration"`
+}
 
-	// Add each row to the table.
-	for _, i := range sortedKeys {
-		var day map[string]models.Entry = consolidatedByDay[i]
-		var totalPerDay int64 = 0
+type reportDay struct {
+	Date     string          `json:"date"`
+	Projects []reportProject `json:"projects"`
+	Total    reportDuration  `json:"total"`
+}
 
-		for p, v := range day {
-			t.AppendRow(table.Row{i, p, v.GetTasksAsString(), secondsToHuman(v.Duration)})
-			totalPerDay += round(v.Duration)
-		}
+// A report is everything the report shows, computed from the entries, before
+// it is rendered in one of the report formats.
+type report struct {
+	Version        int             `json:"version"`
+	From           string          `json:"from"`
+	To             string          `json:"to"`
+	RoundToMinutes int64           `json:"round_to_minutes"`
+	Totals         reportTotals    `json:"totals"`
+	ByProject      []reportProject `json:"by_project"`
+	ByTask         []reportTask    `json:"by_task"`
+	ByEntry        []reportEntry   `json:"by_entry"`
+	ByDay          []reportDay     `json:"by_day"`
+}
 
-		if show_by_day_totals {
-			t.AppendSeparator()
-			t.AppendRow(table.Row{"", "", constants.TOTAL, secondsToHMS(totalPerDay)})
-			t.AppendSeparator()
+// The version of the report's JSON schema, bumped whenever a field is renamed
+// or removed.
+const reportVersion int = 1
+
+// Compute the report of the entries between start and end.
+// Compute the report, with days running from midnight to midnight in the time
+// zone, from the durations calculated with round_to_minutes.
+func computeReport(start carbon.Carbon, end carbon.Carbon, durations map[int64]models.UID, entries []models.Entry, timezone string, roundToMinutes int64) report {
+	return report{
+		Version:        reportVersion,
+		From:           start.ToRfc3339String(),
+		To:             end.ToRfc3339String(),
+		RoundToMinutes: roundToMinutes,
+		Totals:         computeTotals(durations, entries),
+		ByProject:      computeByProject(durations, entries),
+		ByTask:         computeByTask(durations, entries),
+		ByEntry:        computeByEntry(durations, entries, timezone),
+		ByDay:          computeByDay(durations, entries, timezone),
+	}
+}
+
+// Append the entry's tasks, that are not already in the tasks, to the tasks.
+func appendTasks(tasks []string, e models.Entry) []string {
+	for _, p := range e.Properties {
+		if strings.EqualFold(p.Name, constants.TASK) && !slices.Contains(tasks, p.Value) {
+			tasks = append(tasks, p.Value)
 		}
 	}
 
-	// Render the table.
-	log.Println(t.Render())
+	return tasks
 }
 
-func reportByEntry(durations map[int64]models.UID, entries []models.Entry) {
-	log.Printf("\n")
-	log.Printf("%s\n", dashes(" By Entry "))
-	log.Printf("\n")
+func computeTotals(durations map[int64]models.UID, entries []models.Entry) reportTotals {
+	var totals reportTotals
 
-	// Consolidate
-	var consolidatedByUid map[int64]models.Entry = make(map[int64]models.Entry)
+	// Calculate total time worked and total times on break.
 	for _, e := range entries {
-		var task = e.GetTasksAsString()
-		// Check if the project exists in the map or not.
-		consolidated, found := consolidatedByUid[e.Uid]
-		if found {
-			if len(task) > 0 {
-				consolidated.AddEntryProperty(constants.TASK, task)
-			}
-
-			// Add the consolidated object to the collection.
-			consolidatedByUid[e.Uid] = consolidated
+		// Skip HELLOs.
+		if strings.EqualFold(e.Project, constants.HELLO) {
+			continue
+		} else if strings.EqualFold(e.Project, constants.BREAK) {
+			totals.Break.add(durations[e.Uid])
 		} else {
-			var entry models.Entry = models.NewEntry(e.Uid, e.Project, e.Note, e.EntryDatetime)
-			entry.Duration = durations[e.Uid].Duration
-			if len(task) > 0 {
-				entry.AddEntryProperty(constants.TASK, task)
-			}
-			consolidatedByUid[e.Uid] = entry
+			totals.Work.add(durations[e.Uid])
 		}
-	}
 
-	// Since maps are not sorter in go... why, I have no idea, you need to first
-	// sort the keys and then access the map via those sorted keys.
-	var sortedKeys []int64 = make([]int64, 0, len(consolidatedByUid))
-	for key := range consolidatedByUid {
-		sortedKeys = append(sortedKeys, key)
+		totals.Total.add(durations[e.Uid])
 	}
-	sort.SliceStable(sortedKeys, func(i, j int) bool { return sortedKeys[i] < sortedKeys[j] })
-
-	// Create and configure the table.
-	var t table.Writer = table.NewWriter()
-	t.Style().Options.DrawBorder = false
-	t.AppendHeader(table.Row{constants.DATE_NORMAL_CASE, constants.START_END_NORMAL_CASE, constants.DURATION_NORMAL_CASE, constants.PROJECT_NORMAL_CASE, constants.TASK_NORMAL_CASE, constants.NOTE_NORMAL_CASE})
 
-	// Add all the consolidated rows to the table.
-	for _, i := range sortedKeys {
-		var entry models.Entry = consolidatedByUid[i]
+	return totals
+}
 
+func computeByProject(durations map[int64]models.UID, entries []models.Entry) []reportProject {
+	// Consolidate by project.
+	var consolidatedByProject map[string]reportProject = make(map[string]reportProject)
+	for _, e := range entries {
 		// Skip entries that match constants.HELLO.
-		if !strings.EqualFold(entry.Project, constants.HELLO) {
-			t.AppendRow(table.Row{
-				carbon.Parse(entry.EntryDatetime).Format(constants.CARBON_DATE_FORMAT),
-				carbon.Parse(entry.EntryDatetime).SubSeconds(int(entry.Duration)).Format(constants.CARBON_START_END_TIME_FORMAT) + " to " + carbon.Parse(entry.EntryDatetime).Format(constants.CARBON_START_END_TIME_FORMAT),
-				secondsToHuman(round(entry.Duration)),
-				entry.Project,
-				entry.GetTasksAsString(),
-				entry.Note})
+		if strings.EqualFold(e.Project, constants.HELLO) {
+			continue
 		}
-	}
 
-	// Render the table.
-	log.Println(t.Render())
-}
+		consolidated, found := consolidatedByProject[e.Project]
+		if !found {
+			consolidated = reportProject{Project: e.Project, Tasks: []string{}}
+		}
 
-func reportByLastEntry() {
-	db := database.New(viper.GetString(constants.DATABASE_FILE))
-	var entry models.Entry = db.GetLastEntry()
-	if strings.EqualFold(entry.Project, constants.HELLO) ||
-		strings.EqualFold(entry.Project, constants.BREAK) {
-		log.Printf("DateTime: %s\n      Project: %s\n    Note: %s\n", carbon.Parse(entry.EntryDatetime).Format("Y-m-d g:i:sa"), entry.Project, entry.Note)
-	} else {
-		log.Printf("DateTime: %s\n Project: %s\n   Tasks: %s\n    Note: %s\n", carbon.Parse(entry.EntryDatetime).Format("Y-m-d g:i:sa"), entry.Project, entry.GetTasksAsString(), entry.Note)
+		consolidated.Tasks = appendTasks(consolidated.Tasks, e)
+		consolidated.Duration.add(durations[e.Uid])
+		consolidatedByProject[e.Project] = consolidated
 	}
-}
 
-func reportByProject(durations map[int64]models.UID, entries []models.Entry) {
-	log.Printf("\n")
-	log.Printf("%s\n", dashes(" By Project "))
-	log.Printf("\n")
+	var projects []reportProject = make([]reportProject, 0, len(consolidatedByProject))
+	for _, p := range consolidatedByProject {
+		projects = append(projects, p)
+	}
+	sort.SliceStable(projects, func(i, j int) bool { return projects[i].Project < projects[j].Project })
 
-	// Consolidate by project.
-	var consolidatedByProject map[string]models.Entry = make(map[string]models.Entry)
-	for _, e := range entries {
-		// Check if the project exists in the map or not.
-		consolidated, found := consolidatedByProject[e.Project]
-		if found {
-			if len(e.GetTasksAsString()) > 0 {
-				consolidated.AddEntryProperty(constants.TASK, e.GetTasksAsString())
-			}
+	return projects
+}
 
-			// If the Uid changes, add the new duration.
-			if consolidated.Uid != e.Uid {
-				consolidated.Uid = e.Uid
-				consolidated.Duration += round(durations[e.Uid].Duration)
-			}
+func computeByTask(durations map[int64]models.UID, entries []models.Entry) []reportTask {
+	var consolidatedByTask map[string]reportTask = make(map[string]reportTask)
+	for _, e := range entries {
+		if strings.EqualFold(e.Project, constants.HELLO) {
+			continue
+		}
 
-			// Add the consolidated object to the collection.
-			consolidatedByProject[e.Project] = consolidated
-		} else {
-			var entry models.Entry = models.NewEntry(e.Uid, e.Project, e.Note, e.EntryDatetime)
-			entry.Duration = round(durations[e.Uid].Duration)
-			if len(e.GetTasksAsString()) > 0 {
-				entry.AddEntryProperty(constants.TASK, e.GetTasksAsString())
-			}
-			consolidatedByProject[e.Project] = entry
+		var t string = e.GetTasksAsString()
+		consolidated, found := consolidatedByTask[t]
+		if !found {
+			consolidated = reportTask{Tasks: t, Url: e.GetUrlAsString()}
 		}
-	}
 
-	// Since maps are not sorted in go... why, I have no idea, you need to first
-	// sort the keys and then access the map via those sorted keys.
-	var sortedKeys []string = make([]string, 0, len(consolidatedByProject))
-	for key := range consolidatedByProject {
-		sortedKeys = append(sortedKeys, key)
-	}
-	sort.SliceStable(sortedKeys, func(i, j int) bool { return sortedKeys[i] < sortedKeys[j] })
+		if !slices.Contains(consolidated.Projects, e.Project) {
+			consolidated.Projects = append(consolidated.Projects, e.Project)
+		}
 
-	// Create and configure the table.
-	var t table.Writer = table.NewWriter()
-	t.Style().Options.DrawBorder = false
-	t.AppendHeader(table.Row{constants.PROJECT_NORMAL_CASE, constants.TASK_NORMAL_CASE, constants.DURATION_NORMAL_CASE})
+		if stringUtils.IsEmpty(consolidated.Url) {
+			consolidated.Url = e.GetUrlAsString()
+		}
 
-	// Add all the consolidated rows to the table.
-	for _, i := range sortedKeys {
-		var entry models.Entry = consolidatedByProject[i]
+		consolidated.Duration.add(durations[e.Uid])
+		consolidatedByTask[t] = consolidated
+	}
 
-		// Skip entries that match constants.HELLO.
-		if !strings.EqualFold(entry.Project, constants.HELLO) {
-			t.AppendRow(table.Row{entry.Project, entry.GetTasksAsString(), secondsToHuman(entry.Duration)})
-		}
+	var tasks []reportTask = make([]reportTask, 0, len(consolidatedByTask))
+	for _, t := range consolidatedByTask {
+		tasks = append(tasks, t)
 	}
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].Tasks < tasks[j].Tasks })
 
-	// Render the table.
-	log.Println(t.Render())
+	return tasks
 }
 
-func reportByTask(durations map[int64]models.UID, entries []models.Entry) {
-	log.Printf("\n")
-	log.Printf("%s\n", dashes(" By Task "))
-	log.Printf("\n")
-
-	var consolidateByTask map[string]models.Task = make(map[string]models.Task)
+// Compute the entries, one each, with their start and end times and durations
+// in the time zone.
+func computeByEntry(durations map[int64]models.UID, entries []models.Entry, timezone string) []reportEntry {
+	var rows []reportEntry = make([]reportEntry, 0, len(entries))
 	for _, e := range entries {
+		// Skip entries that match constants.HELLO.
 		if strings.EqualFold(e.Project, constants.HELLO) {
 			continue
-		} else {
-			var t = e.GetTasksAsString()
-			consolidated, found := consolidateByTask[t]
-			if found {
-				consolidated.Duration += round(durations[e.Uid].Duration)
-				consolidateByTask[t] = consolidated
-			} else {
-				var task models.Task = models.NewTask(t)
-				task.Duration = round(durations[e.Uid].Duration)
-				task.AddTaskProperty(constants.PROJECT, e.Project)
-				task.AddTaskProperty(constants.URL, e.GetUrlAsString())
-				consolidateByTask[t] = task
-			}
 		}
-	}
 
-	// Check and see if any entry has a URL property.  If so, add it to the table.
-	var urlFound bool = false
-	for _, v := range consolidateByTask {
-		if len(v.GetUrlAsString()) > 0 {
-			urlFound = true
-			break
+		var end carbon.Carbon = carbon.Parse(e.EntryDatetime, timezone)
+		var row reportEntry = reportEntry{
+			Uid:     e.Uid,
+			Date:    end.Format(constants.CARBON_DATE_FORMAT),
+			Start:   end.SubSeconds(int(durations[e.Uid].Duration)).ToRfc3339String(),
+			End:     end.ToRfc3339String(),
+			Project: e.Project,
+			Tasks:   appendTasks([]string{}, e),
+			Note:    e.Note,
+			Url:     e.GetUrlAsString(),
 		}
-	}
 
-	// Create and configure the table.
-	var t table.Writer = table.NewWriter()
-	t.Style().Options.DrawBorder = false
-	if !urlFound {
-		t.AppendHeader(table.Row{constants.TASKS_NORMAL_CASE, constants.PROJECTS_NORMAL_CASE, constants.DURATION_NORMAL_CASE})
-	} else {
-		t.AppendHeader(table.Row{constants.TASKS_NORMAL_CASE, constants.PROJECTS_NORMAL_CASE, constants.DURATION_NORMAL_CASE, constants.URL_NORMAL_CASE})
+		row.Duration.add(durations[e.Uid])
+		rows = append(rows, row)
 	}
 
-	// Populate the table.
-	for _, v := range consolidateByTask {
-		if !urlFound {
-			t.AppendRow(table.Row{v.Task, v.GetProjectsAsString(), secondsToHuman(v.Duration)})
-		} else {
-			t.AppendRow(table.Row{v.Task, v.GetProjectsAsString(), secondsToHuman(v.Duration), v.GetUrlAsString()})
-		}
-	}
-
-	// Render the table.
-	log.Println(t.Render())
+	return rows
 }
 
-func reportTotalWorkAndBreakTime(durations map[int64]models.UID, entries []models.Entry) {
-	var totalWorkDuration int64 = 0
-	var totalBreakDuration int64 = 0
-
-	// Calculate total time worked and total times on break.
+func computeByDay(durations map[int64]models.UID, entries []models.Entry, timezone string) []reportDay {
+	// Consolidate by day, and then by project in the order each project was
+	// first worked on that day.
+	var days []reportDay = make([]reportDay, 0)
 	for _, e := range entries {
-		// Skip HELLOs.
 		if strings.EqualFold(e.Project, constants.HELLO) {
 			continue
-		} else if strings.EqualFold(e.Project, constants.BREAK) {
-			totalBreakDuration += round(durations[e.Uid].Duration)
-		} else {
-			totalWorkDuration += round(durations[e.Uid].Duration)
 		}
-	}
 
-	log.Printf("\n")
-
-	// If we have worked more seconds than are in a day, we need to show hours,
-	// minutes, and seconds as well as the human readable form of the duration.
-	// By showing the hours, minutes, and seconds, we have a better
-	// representation of our duration.  For example... traditionally, a person
-	// works 40 hours a week.  If the report tells us we worked 1 day and 3
-	// hours... we have to convert that in our heads to 27 hours... But if the
-	// report simply did the conversion for us... that is much better.
-	if viper.GetBool(constants.SPLIT_WORK_FROM_BREAK_TIME) {
-		if totalWorkDuration > constants.SECONDS_PER_DAY {
-			log.Printf("Total Working Time: %s (%s)\n", secondsToHuman(totalWorkDuration), secondsToHMS(totalWorkDuration))
-		} else {
-			log.Printf("Total Working Time: %s\n", secondsToHuman(totalWorkDuration))
+		var date string = carbon.Parse(e.EntryDatetime, timezone).Format(constants.CARBON_DATE_FORMAT)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, reportDay{Date: date, Projects: []reportProject{}})
 		}
 
-		log.Printf("  Total Break Time: %s\n", secondsToHuman(totalBreakDuration))
-	} else {
-		var total = totalWorkDuration + totalBreakDuration
-		if totalWorkDuration > constants.SECONDS_PER_DAY {
-			log.Printf("Total Time: %s (%s)\n", secondsToHuman(total), secondsToHMS(total))
-		} else {
-			log.Printf("Total Time: %s\n", secondsToHuman(total))
+		var day *reportDay = &days[len(days)-1]
+		var index int = slices.IndexFunc(day.Projects, func(p reportProject) bool { return p.Project == e.Project })
+		if index < 0 {
+			day.Projects = append(day.Projects, reportProject{Project: e.Project, Tasks: []string{}})
+			index = len(day.Projects) - 1
 		}
+
+		day.Projects[index].Tasks = appendTasks(day.Projects[index].Tasks, e)
+		day.Projects[index].Duration.add(durations[e.Uid])
+		day.Total.add(durations[e.Uid])
+	}
+
+	return days
+}
+
+func reportByLastEntry(store database.Store, timezone string) {
+	entry, err := store.GetLastEntry()
+	exitOnError(err)
+
+	if strings.EqualFold(entry.Project, constants.HELLO) ||
+		strings.EqualFold(entry.Project, constants.BREAK) {
+		log.Printf("DateTime: %s\n      Project: %s\n    Note: %s\n", carbon.Parse(entry.EntryDatetime, timezone).Format("Y-m-d g:i:sa"), entry.Project, entry.Note)
+	} else {
+		log.Printf("DateTime: %s\n Project: %s\n   Tasks: %s\n    Note: %s\n", carbon.Parse(entry.EntryDatetime, timezone).Format("Y-m-d g:i:sa"), entry.Project, entry.GetTasksAsString(), entry.Note)
 	}
 }
 
-func round(durationInSeconds int64) (result int64) {
+func round(durationInSeconds int64, roundToMinutes int64) (result int64) {
 	var seconds int64 = durationInSeconds
 
 	if roundToMinutes > 0 {
@@ -438,17 +374,16 @@ func round(durationInSeconds int64) (result int64) {
 	return (seconds)
 }
 
-func runReport(cmd *cobra.Command, _ []string) {
+func runReport(cmd *cobra.Command, _ []string, store database.Store) {
 	var start carbon.Carbon
 	var end carbon.Carbon
 
 	// See if the user asked to override round.  If no, use the rounding value
 	// from the configuration file.  Otherwise, set the rounding value to 0.
 	noRounding, _ := cmd.Flags().GetBool("no-rounding")
+	var roundToMinutes int64 = 0
 	if !noRounding {
 		roundToMinutes = viper.GetInt64(constants.ROUND_TO_MINUTES)
-	} else {
-		roundToMinutes = 0
 	}
 
 	currentWeek, _ := cmd.Flags().GetBool("current-week")
@@ -456,11 +391,31 @@ func runReport(cmd *cobra.Command, _ []string) {
 	lastEntry, _ := cmd.Flags().GetBool("last-entry")
 	fromDateStr, _ := cmd.Flags().GetString("from")
 	toDateStr, _ := cmd.Flags().GetString("to")
+	includeArchives, _ := cmd.Flags().GetBool(constants.INCLUDE_ARCHIVES)
+	tz, _ := cmd.Flags().GetString(constants.TZ)
+	format, _ := cmd.Flags().GetString(constants.FORMAT)
 
-	var reportNow = carbon.Now()
+	if !slices.Contains(reportFormats, format) {
+		log.Fatalf("%s: Invalid format[%s].  Please use one of %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), format, strings.Join(reportFormats, ", "))
+	}
+
+	// Days start and end at midnight in the report's time zone, which also
+	// makes the days daylight saving time starts and ends on 23 and 25 hours
+	// long.
+	if stringUtils.IsEmpty(tz) {
+		tz = viper.GetString(constants.TIMEZONE)
+	}
+
+	if stringUtils.IsEmpty(tz) {
+		tz = carbon.Local
+	} else if _, err := time.LoadLocation(tz); err != nil {
+		log.Fatalf("%s: Invalid time zone[%s].  Please use an IANA time zone, e.g. America/New_York.\n", color.RedString(constants.FATAL_NORMAL_CASE), tz)
+	}
+
+	var reportNow = carbon.Now(tz)
 
 	if lastEntry {
-		reportByLastEntry()
+		reportByLastEntry(store, tz)
 		os.Exit(0)
 	} else if stringUtils.IsEmpty(fromDateStr) &&
 		stringUtils.IsEmpty(toDateStr) &&
@@ -472,43 +427,25 @@ func runReport(cmd *cobra.Command, _ []string) {
 		start, end = dateRange(reportNow.SubWeek())
 	} else if !stringUtils.IsEmpty(fromDateStr) &&
 		!stringUtils.IsEmpty(toDateStr) {
-		start = carbon.Parse(fromDateStr)
-		end = carbon.Parse(toDateStr)
+		start = carbon.Parse(fromDateStr, tz).StartOfDay()
+		end = carbon.Parse(toDateStr, tz).EndOfDay()
 	} else {
 		// Report for today.
-		start = carbon.Now().StartOfDay()
-		end = carbon.Now().EndOfDay()
+		start = reportNow.StartOfDay()
+		end = reportNow.EndOfDay()
 	}
 
-	var startWeek int = start.WeekOfYear()
-	var endWeek int = end.WeekOfYear()
-
-	log.Printf("%s\n", dashes(fmt.Sprintf("%s(%d) to %s(%d)",
-		start, startWeek, end, endWeek)))
-
-	// Get the unique UIDs between the specified start and end dates.
-	db := database.New(viper.GetString(constants.DATABASE_FILE))
-	var distinctUIDs []database.DistinctUID = db.GetDistinctUIDs(start, end)
+	// Get all the Entries between the specified start and end dates.
+	entries, err := getEntriesBetween(store, start, end, includeArchives)
+	exitOnError(err)
 
 	if viper.GetBool("debug") {
-		log.Printf("\n*****\nGetDistinctUIDs returned...\n*****\n")
-	}
-
-	// Declare the "IN" string used in the db.GetEntries() call.
-	var in string = constants.EMPTY
-
-	// Loop through the distinct UIDs and pull out the UID and construct the
-	// "in" statement for later use.
-	for _, element := range distinctUIDs {
-		if viper.GetBool("debug") {
-			log.Printf("%d, %s, %s\n", element.Uid, element.Project, element.EntryDatetime)
-		}
-
-		if !stringUtils.IsEmpty(in) {
-			in = in + ", "
+		log.Printf("\n*****\nDumping what GetEntriesBetween() returned...\n*****\n")
+		for _, element := range entries {
+			log.Printf("%d, %s, %#v, %s, %#v\n",
+				element.Uid, element.Project, element.Note, element.EntryDatetime,
+				element.GetPropertiesAsString())
 		}
-
-		in = in + strconv.FormatInt(element.Uid, 10)
 	}
 
 	// Calculate the duration between each UID.
@@ -516,38 +453,7 @@ func runReport(cmd *cobra.Command, _ []string) {
 		log.Printf("\n*****\nCalculating Durations...\n*****\n")
 	}
 
-	var durations map[int64]models.UID = make(map[int64]models.UID)
-	for i := range distinctUIDs {
-		// Check to see if the 1st element we have is a HELLO.  If not, we need to adjust
-		// accordingly.
-		if i == 0 || strings.EqualFold(distinctUIDs[i].Project, constants.HELLO) {
-			var current carbon.Carbon = carbon.Parse(distinctUIDs[i].EntryDatetime)
-			if current.Error != nil {
-				log.Fatalf("%s: Unable to parse EntryDateTime. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), current.Error)
-				os.Exit(1)
-			}
-
-			// Prior is Midnight since this is the 1st record.
-			var midnight carbon.Carbon = current.StartOfDay()
-			var uid models.UID = models.NewUID(distinctUIDs[i].Uid, distinctUIDs[i].EntryDatetime, current.DiffAbsInSeconds(midnight))
-			durations[distinctUIDs[i].Uid] = uid
-		} else {
-			var current carbon.Carbon = carbon.Parse(distinctUIDs[i].EntryDatetime)
-			if current.Error != nil {
-				log.Fatalf("%s: Unable to parse EntryDateTime. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), current.Error)
-				os.Exit(1)
-			}
-
-			var prior carbon.Carbon = carbon.Parse(distinctUIDs[i-1].EntryDatetime)
-			if prior.Error != nil {
-				log.Fatalf("%s: Unable to parse EntryDateTime. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), prior.Error)
-				os.Exit(1)
-			}
-
-			var uid models.UID = models.NewUID(distinctUIDs[i].Uid, distinctUIDs[i].EntryDatetime, current.DiffAbsInSeconds(prior))
-			durations[distinctUIDs[i].Uid] = uid
-		}
-	}
+	var durations map[int64]models.UID = calculateDurations(entries, tz, roundToMinutes)
 
 	// If requested, dump all the data with the newly rounded durations.
 	if viper.GetBool("debug") {
@@ -566,39 +472,47 @@ func runReport(cmd *cobra.Command, _ []string) {
 		for _, i := range sortedKeys {
 			log.Printf("Key[%d] Uid[%d] EntryDatetime[%s] Duration[%d or %s]\n",
 				i, durations[i].Uid, durations[i].EntryDatetime, durations[i].Duration,
-				secondsToHuman(durations[i].Duration))
+				secondsToHuman(durations[i].Duration, roundToMinutes))
 		}
 	}
 
-	// Get all the Entries associated with the list of UIDs.
-	var entries []models.Entry = db.GetEntries(in)
-	if viper.GetBool("debug") {
-		log.Printf("\n*****\nDumping what GetEntries() returned...\n*****\n")
-		for _, element := range entries {
-			log.Printf("%d, %s, %#v, %s, %#v\n",
-				element.Uid, element.Project, element.Note, element.EntryDatetime,
-				element.GetPropertiesAsString())
-		}
-	}
-
-	// Run each of the reports, if configured to do so.
-	reportTotalWorkAndBreakTime(durations, entries)
+	err = renderReport(os.Stdout, format, computeReport(start, end, durations, entries, tz, roundToMinutes))
+	exitOnError(err)
+}
 
-	if viper.GetBool(constants.REPORT_BY_PROJECT) {
-		reportByProject(durations, entries)
-	}
+// Calculate the duration of each of the entries, which must be ordered by
+// date/time, both as recorded and rounded to roundToMinutes.  An entry's
+// duration is the time since the entry before it, or since midnight in the time
+// zone for the first entry and for each HELLO.
+func calculateDurations(entries []models.Entry, timezone string, roundToMinutes int64) map[int64]models.UID {
+	var durations map[int64]models.UID = make(map[int64]models.UID)
+	for i := range entries {
+		var current carbon.Carbon = carbon.Parse(entries[i].EntryDatetime, timezone)
+		if current.Error != nil {
+			log.Fatalf("%s: Unable to parse EntryDateTime. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), current.Error)
+			os.Exit(1)
+		}
 
-	if viper.GetBool(constants.REPORT_BY_TASK) {
-		reportByTask(durations, entries)
-	}
+		// Check to see if the 1st element we have is a HELLO.  If not, we need to adjust
+		// accordingly.
+		if i == 0 || strings.EqualFold(entries[i].Project, constants.HELLO) {
+			// Prior is Midnight since this is the 1st record.
+			var midnight carbon.Carbon = current.StartOfDay()
+			var duration int64 = current.DiffAbsInSeconds(midnight)
+			durations[entries[i].Uid] = models.NewUID(entries[i].Uid, entries[i].EntryDatetime, duration, round(duration, roundToMinutes))
+		} else {
+			var prior carbon.Carbon = carbon.Parse(entries[i-1].EntryDatetime)
+			if prior.Error != nil {
+				log.Fatalf("%s: Unable to parse EntryDateTime. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), prior.Error)
+				os.Exit(1)
+			}
 
-	if viper.GetBool(constants.REPORT_BY_ENTRY) {
-		reportByEntry(durations, entries)
+			var duration int64 = current.DiffAbsInSeconds(prior)
+			durations[entries[i].Uid] = models.NewUID(entries[i].Uid, entries[i].EntryDatetime, duration, round(duration, roundToMinutes))
+		}
 	}
 
-	if viper.GetBool(constants.REPORT_BY_DAY) {
-		reportByDay(durations, entries)
-	}
+	return durations
 }
 
 func secondsToHMS(inSeconds int64) (result string) {
@@ -618,7 +532,7 @@ func secondsToHMS(inSeconds int64) (result string) {
 	return stringUtils.Trim(result)
 }
 
-func secondsToHuman(inSeconds int64) (result string) {
+func secondsToHuman(inSeconds int64, roundToMinutes int64) (result string) {
 	// If the duration is zero, this means than the rounded value is less than
 	// the "round to minutes" value, simply show a less than message.
 	if inSeconds == 0 {
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/viper"
+)
+
+// The formats the report can be rendered in.
+var reportFormats = []string{"table", "json", "csv", "markdown"}
+
+// The columns of the CSV report.  Each row is a row of one of the report's
+// sections, so only the columns that section has are filled in.
+var reportCsvHeader = []string{"section", "date", "start", "end", "project", "tasks", "note", "url", "seconds", "raw_seconds"}
+
+// Render the report in the format.
+func renderReport(w io.Writer, format string, r report) error {
+	switch format {
+	case "json":
+		var encoder *json.Encoder = json.NewEncoder(w)
+		encoder.SetIndent(constants.EMPTY, "  ")
+		return encoder.Encode(r)
+	case "csv":
+		return renderReportCsv(w, r)
+	case "markdown":
+		return renderReportMarkdown(w, r)
+	default:
+		return renderReportTable(w, r)
+	}
+}
+
+// Parse a date/time from the report, keeping the offset of the report's time
+// zone it was written with rather than moving it to the local one.
+func parseReportDatetime(value string) carbon.Carbon {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return carbon.Parse(value)
+	}
+
+	return carbon.CreateFromStdTime(t)
+}
+
+func renderReportTable(w io.Writer, r report) error {
+	var start carbon.Carbon = parseReportDatetime(r.From)
+	var end carbon.Carbon = parseReportDatetime(r.To)
+	fmt.Fprintf(w, "%s\n", dashes(fmt.Sprintf("%s(%d) to %s(%d)", start.ToDateTimeString(), start.WeekOfYear(), end.ToDateTimeString(), end.WeekOfYear())))
+
+	fmt.Fprintf(w, "\n")
+	for _, line := range totalsLines(r.Totals, r.RoundToMinutes) {
+		fmt.Fprintf(w, "%s\n", line)
+	}
+
+	for _, section := range reportSections(r) {
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "%s\n", dashes(" "+section.Title+" "))
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintln(w, section.Table.Render())
+	}
+
+	return nil
+}
+
+func renderReportMarkdown(w io.Writer, r report) error {
+	fmt.Fprintf(w, "# Report from %s to %s\n\n", parseReportDatetime(r.From).ToDateString(), parseReportDatetime(r.To).ToDateString())
+	for _, line := range totalsLines(r.Totals, r.RoundToMinutes) {
+		fmt.Fprintf(w, "* %s\n", strings.TrimSpace(line))
+	}
+
+	for _, section := range reportSections(r) {
+		fmt.Fprintf(w, "\n## %s\n\n", section.Title)
+		fmt.Fprintln(w, section.Table.RenderMarkdown())
+	}
+
+	return nil
+}
+
+func renderReportCsv(w io.Writer, r report) error {
+	var writer *csv.Writer = csv.NewWriter(w)
+	writer.Write(reportCsvHeader)
+
+	var seconds = func(d reportDuration) []string {
+		return []string{strconv.FormatInt(d.Seconds, 10), strconv.FormatInt(d.RawSeconds, 10)}
+	}
+
+	if viper.GetBool(constants.REPORT_BY_PROJECT) {
+		for _, p := range r.ByProject {
+			writer.Write(append([]string{"project", constants.EMPTY, constants.EMPTY, constants.EMPTY, p.Project, strings.Join(p.Tasks, ", "), constants.EMPTY, constants.EMPTY}, seconds(p.Duration)...))
+		}
+	}
+
+	if viper.GetBool(constants.REPORT_BY_TASK) {
+		for _, t := range r.ByTask {
+			writer.Write(append([]string{"task", constants.EMPTY, constants.EMPTY, constants.EMPTY, strings.Join(t.Projects, ", "), t.Tasks, constants.EMPTY, t.Url}, seconds(t.Duration)...))
+		}
+	}
+
+	if viper.GetBool(constants.REPORT_BY_ENTRY) {
+		for _, e := range r.ByEntry {
+			writer.Write(append([]string{"entry", e.Date, e.Start, e.End, e.Project, strings.Join(e.Tasks, ", "), e.Note, e.Url}, seconds(e.Duration)...))
+		}
+	}
+
+	if viper.GetBool(constants.REPORT_BY_DAY) {
+		for _, d := range r.ByDay {
+			for _, p := range d.Projects {
+				writer.Write(append([]string{"day", d.Date, constants.EMPTY, constants.EMPTY, p.Project, strings.Join(p.Tasks, ", "), constants.EMPTY, constants.EMPTY}, seconds(p.Duration)...))
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// The lines giving the total time worked and on break.
+func totalsLines(totals reportTotals, roundToMinutes int64) []string {
+	var work int64 = totals.Work.Seconds
+
+	// If we have worked more seconds than are in a day, we need to show hours,
+	// minutes, and seconds as well as the human readable form of the duration.
+	// By showing the hours, minutes, and seconds, we have a better
+	// representation of our duration.  For example... traditionally, a person
+	// works 40 hours a week.  If the report tells us we worked 1 day and 3
+	// hours... we have to convert that in our heads to 27 hours... But if the
+	// report simply did the conversion for us... that is much better.
+	if viper.GetBool(constants.SPLIT_WORK_FROM_BREAK_TIME) {
+		var lines []string
+		if work > constants.SECONDS_PER_DAY {
+			lines = append(lines, fmt.Sprintf("Total Working Time: %s (%s)", secondsToHuman(work, roundToMinutes), secondsToHMS(work)))
+		} else {
+			lines = append(lines, fmt.Sprintf("Total Working Time: %s", secondsToHuman(work, roundToMinutes)))
+		}
+
+		return append(lines, fmt.Sprintf("  Total Break Time: %s", secondsToHuman(totals.Break.Seconds, roundToMinutes)))
+	}
+
+	var total int64 = totals.Total.Seconds
+	if work > constants.SECONDS_PER_DAY {
+		return []string{fmt.Sprintf("Total Time: %s (%s)", secondsToHuman(total, roundToMinutes), secondsToHMS(total))}
+	}
+
+	return []string{fmt.Sprintf("Total Time: %s", secondsToHuman(total, roundToMinutes))}
+}
+
+// A reportSection is one of the report's sections as a table.
+type reportSection struct {
+	Title string
+	Table table.Writer
+}
+
+// The report's sections, if configured to show them.
+func reportSections(r report) []reportSection {
+	var sections []reportSection
+	if viper.GetBool(constants.REPORT_BY_PROJECT) {
+		sections = append(sections, reportSection{"By Project", projectTable(r.ByProject, r.RoundToMinutes)})
+	}
+
+	if viper.GetBool(constants.REPORT_BY_TASK) {
+		sections = append(sections, reportSection{"By Task", taskTable(r.ByTask, r.RoundToMinutes)})
+	}
+
+	if viper.GetBool(constants.REPORT_BY_ENTRY) {
+		sections = append(sections, reportSection{"By Entry", entryTable(r.ByEntry, r.RoundToMinutes)})
+	}
+
+	if viper.GetBool(constants.REPORT_BY_DAY) {
+		sections = append(sections, reportSection{"By Day", dayTable(r.ByDay, r.RoundToMinutes)})
+	}
+
+	return sections
+}
+
+// Render the entries, one row each, with their start and end times in the time
+// zone and their durations calculated with round_to_minutes.
+func renderByEntry(durations map[int64]models.UID, entries []models.Entry, timezone string, roundToMinutes int64) string {
+	return entryTable(computeByEntry(durations, entries, timezone), roundToMinutes).Render()
+}
+
+func projectTable(projects []reportProject, roundToMinutes int64) table.Writer {
+	var t table.Writer = table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.AppendHeader(table.Row{constants.PROJECT_NORMAL_CASE, constants.TASK_NORMAL_CASE, constants.DURATION_NORMAL_CASE})
+	for _, p := range projects {
+		t.AppendRow(table.Row{p.Project, strings.Join(p.Tasks, ", "), secondsToHuman(p.Duration.Seconds, roundToMinutes)})
+	}
+
+	return t
+}
+
+func taskTable(tasks []reportTask, roundToMinutes int64) table.Writer {
+	// Check and see if any task has a URL.  If so, add it to the table.
+	var urlFound bool = false
+	for _, t := range tasks {
+		if len(t.Url) > 0 {
+			urlFound = true
+			break
+		}
+	}
+
+	var t table.Writer = table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	if !urlFound {
+		t.AppendHeader(table.Row{constants.TASKS_NORMAL_CASE, constants.PROJECTS_NORMAL_CASE, constants.DURATION_NORMAL_CASE})
+	} else {
+		t.AppendHeader(table.Row{constants.TASKS_NORMAL_CASE, constants.PROJECTS_NORMAL_CASE, constants.DURATION_NORMAL_CASE, constants.URL_NORMAL_CASE})
+	}
+
+	for _, v := range tasks {
+		if !urlFound {
+			t.AppendRow(table.Row{v.Tasks, strings.Join(v.Projects, ", "), secondsToHuman(v.Duration.Seconds, roundToMinutes)})
+		} else {
+			t.AppendRow(table.Row{v.Tasks, strings.Join(v.Projects, ", "), secondsToHuman(v.Duration.Seconds, roundToMinutes), v.Url})
+		}
+	}
+
+	return t
+}
+
+func entryTable(entries []reportEntry, roundToMinutes int64) table.Writer {
+	var t table.Writer = table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.AppendHeader(table.Row{constants.DATE_NORMAL_CASE, constants.START_END_NORMAL_CASE, constants.DURATION_NORMAL_CASE, constants.PROJECT_NORMAL_CASE, constants.TASK_NORMAL_CASE, constants.NOTE_NORMAL_CASE})
+	for _, e := range entries {
+		t.AppendRow(table.Row{
+			e.Date,
+			parseReportDatetime(e.Start).Format(constants.CARBON_START_END_TIME_FORMAT) + " to " + parseReportDatetime(e.End).Format(constants.CARBON_START_END_TIME_FORMAT),
+			secondsToHuman(e.Duration.Seconds, roundToMinutes),
+			e.Project,
+			strings.Join(e.Tasks, ", "),
+			e.Note})
+	}
+
+	return t
+}
+
+func dayTable(days []reportDay, roundToMinutes int64) table.Writer {
+	var show_by_day_totals bool = viper.GetBool(constants.SHOW_BY_DAY_TOTALS)
+
+	var t table.Writer = table.NewWriter()
+	t.Style().Options.DrawBorder = false
+	t.AppendHeader(table.Row{constants.DATE_NORMAL_CASE, constants.PROJECT_NORMAL_CASE, constants.TASKS_NORMAL_CASE, constants.DURATION_NORMAL_CASE})
+	for _, d := range days {
+		for _, p := range d.Projects {
+			t.AppendRow(table.Row{d.Date, p.Project, strings.Join(p.Tasks, ", "), secondsToHuman(p.Duration.Seconds, roundToMinutes)})
+		}
+
+		if show_by_day_totals {
+			t.AppendSeparator()
+			t.AppendRow(table.Row{"", "", constants.TOTAL, secondsToHMS(d.Total.Seconds)})
+			t.AppendSeparator()
+		}
+	}
+
+	return t
+}
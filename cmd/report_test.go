@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/viper"
+)
+
+func TestAddAndReportAwkwardText(t *testing.T) {
+	// Project and task names cannot hold the "+" that separates them, but
+	// anything else goes.
+	var names = []string{"customer's bug", `say "hello"`, "a; DROP TABLE entry; --", "Ünïcödé ñandú", "日本語", "🚀 launch"}
+
+	var store *database.MemoryStore = database.NewMemoryStore()
+	for _, name := range names {
+		addCmd.Flags().Set(constants.NOTE, "note: "+name)
+		runAdd(addCmd, []string{name + constants.TASK_DELIMITER + "task " + name}, store)
+	}
+
+	addCmd.Flags().Set(constants.NOTE, constants.EMPTY)
+
+	var start carbon.Carbon = carbon.Now().StartOfDay()
+	var end carbon.Carbon = carbon.Now().EndOfDay()
+	entries, err := store.GetEntriesBetween(start, end)
+	if err != nil {
+		t.Fatalf("GetEntriesBetween() failed: %v", err)
+	}
+
+	var r report = computeReport(start, end, calculateDurations(entries, carbon.Local, 0), entries, carbon.Local, 0)
+
+	var buffer bytes.Buffer
+	if err := renderReport(&buffer, "json", r); err != nil {
+		t.Fatalf("renderReport(json) failed: %v", err)
+	}
+
+	var decoded report
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatalf("the json report does not parse: %v\n%s", err, buffer.String())
+	}
+
+	if len(decoded.ByEntry) != len(names) {
+		t.Fatalf("the json report has %d entries, want %d", len(decoded.ByEntry), len(names))
+	}
+
+	for i, e := range decoded.ByEntry {
+		if e.Project != names[i] || e.Note != "note: "+names[i] || len(e.Tasks) != 1 || e.Tasks[0] != "task "+names[i] {
+			t.Errorf("json entry %d is Project[%q] Tasks%q Note[%q], want %q", i, e.Project, e.Tasks, e.Note, names[i])
+		}
+	}
+
+	viper.Set(constants.REPORT_BY_ENTRY, true)
+	defer viper.Set(constants.REPORT_BY_ENTRY, nil)
+
+	buffer.Reset()
+	if err := renderReport(&buffer, "csv", r); err != nil {
+		t.Fatalf("renderReport(csv) failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatalf("the csv report does not parse: %v", err)
+	}
+
+	// The header, then one row per entry.
+	if len(records) != len(names)+1 {
+		t.Fatalf("the csv report has %d rows, want %d", len(records), len(names)+1)
+	}
+
+	for i, record := range records[1:] {
+		if record[4] != names[i] || record[5] != "task "+names[i] || record[6] != "note: "+names[i] {
+			t.Errorf("csv row %d is %q, want %q", i, record, names[i])
+		}
+	}
+}
+
+func TestReportDaylightSavingTimeDays(t *testing.T) {
+	const hour int64 = 60 * 60
+
+	// Entries are stored with the offset they were made at, which need not be
+	// the report's, so the last entry of each day is 23:30 in New York given
+	// in UTC, on the next day.
+	var tests = []struct {
+		name      string
+		date      string
+		entries   []string
+		durations []int64
+		dayLength int64
+	}{
+		{
+			name:      "23-hour day",
+			date:      "2024-03-10",
+			entries:   []string{"2024-03-10T12:00:00-04:00", "2024-03-11T03:30:00Z"},
+			durations: []int64{11 * hour, 11*hour + hour/2},
+			dayLength: 23 * hour,
+		},
+		{
+			name:      "25-hour day",
+			date:      "2024-11-03",
+			entries:   []string{"2024-11-03T12:00:00-05:00", "2024-11-04T04:30:00Z"},
+			durations: []int64{13 * hour, 11*hour + hour/2},
+			dayLength: 25 * hour,
+		},
+		{
+			name:      "24-hour day",
+			date:      "2024-06-01",
+			entries:   []string{"2024-06-01T12:00:00-04:00", "2024-06-02T03:30:00Z"},
+			durations: []int64{12 * hour, 11*hour + hour/2},
+			dayLength: 24 * hour,
+		},
+	}
+
+	const timezone string = "America/New_York"
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var store *database.MemoryStore = database.NewMemoryStore()
+			for _, entryDatetime := range test.entries {
+				var e models.Entry = models.NewEntry(constants.UNKNOWN_UID, "acme", constants.EMPTY, entryDatetime)
+				e.AddEntryProperty(constants.TASK, "dev")
+				if err := store.InsertNewEntry(e, "add"); err != nil {
+					t.Fatalf("InsertNewEntry() failed: %v", err)
+				}
+			}
+
+			// The same start and end as 'tt report --from date --to date'.
+			var start carbon.Carbon = carbon.Parse(test.date, timezone).StartOfDay()
+			var end carbon.Carbon = carbon.Parse(test.date, timezone).EndOfDay()
+			if length := start.DiffAbsInSeconds(end) + 1; length != test.dayLength {
+				t.Errorf("the day is %d hours long, want %d", length/hour, test.dayLength/hour)
+			}
+
+			entries, err := store.GetEntriesBetween(start, end)
+			if err != nil {
+				t.Fatalf("GetEntriesBetween() failed: %v", err)
+			}
+
+			if len(entries) != len(test.entries) {
+				t.Fatalf("GetEntriesBetween() returned %d entries, want %d", len(entries), len(test.entries))
+			}
+
+			var r report = computeReport(start, end, calculateDurations(entries, timezone, 0), entries, timezone, 0)
+			for i, e := range r.ByEntry {
+				if e.Duration.RawSeconds != test.durations[i] {
+					t.Errorf("entry %d lasted %d seconds, want %d", i, e.Duration.RawSeconds, test.durations[i])
+				}
+			}
+
+			var total int64 = 0
+			for _, d := range test.durations {
+				total += d
+			}
+
+			if len(r.ByDay) != 1 || r.ByDay[0].Date != test.date || r.ByDay[0].Total.RawSeconds != total {
+				t.Errorf("the report's days are %+v, want %s lasting %d seconds", r.ByDay, test.date, total)
+			}
+		})
+	}
+}
+
+// Compute a yearly report, the entries of a year out of 100k, one every 15
+// minutes, with their durations.
+func BenchmarkReport(b *testing.B) {
+	var entries []models.Entry = make([]models.Entry, 0, 100_000)
+	var start carbon.Carbon = carbon.CreateFromDate(2024, 1, 1, carbon.UTC).StartOfDay()
+	for i := 0; i < 100_000; i++ {
+		var e models.Entry = models.NewEntry(constants.UNKNOWN_UID, fmt.Sprintf("project %d", i%20), fmt.Sprintf("note %d", i), start.SubMinutes(15*(100_000-i)).ToRfc3339String())
+		e.AddEntryProperty(constants.TASK, fmt.Sprintf("task %d", i%50))
+		entries = append(entries, e)
+	}
+
+	var store *database.MemoryStore = database.NewMemoryStore()
+	if _, err := store.InsertNewEntries(entries, "seed"); err != nil {
+		b.Fatalf("InsertNewEntries() failed: %v", err)
+	}
+
+	var from carbon.Carbon = carbon.CreateFromDate(2023, 1, 1, carbon.UTC).StartOfDay()
+	var to carbon.Carbon = carbon.CreateFromDate(2023, 12, 31, carbon.UTC).EndOfDay()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		loaded, err := store.GetEntriesBetween(from, to)
+		if err != nil {
+			b.Fatalf("GetEntriesBetween() failed: %v", err)
+		}
+
+		var r report = computeReport(from, to, calculateDurations(loaded, carbon.UTC, 0), loaded, carbon.UTC, 0)
+		if len(r.ByEntry) != 365*24*4 {
+			b.Fatalf("the report has %d entries, want %d", len(r.ByEntry), 365*24*4)
+		}
+	}
+}
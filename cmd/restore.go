@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"timetracker/constants"
+	"timetracker/internal/database"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// restoreCmd represents the restore command.
+var restoreCmd = &cobra.Command{
+	Use:   "restore <snapshot>",
+	Args:  cobra.ExactArgs(1),
+	Short: "Replace the database with a snapshot",
+	Long: `Replace the database with a snapshot taken by the backup command.  The
+snapshot can be given by its path or by its name in the backup directory.  The
+snapshot's integrity is checked before anything is replaced, and a snapshot of
+the current database is taken first.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runRestore(cmd, args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+}
+
+func runRestore(_ *cobra.Command, args []string) {
+	var path string = args[0]
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		path = filepath.Join(viper.GetString(constants.BACKUP_DIRECTORY), args[0])
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		log.Fatalf("%s: Snapshot[%s] not found.\n", color.RedString(constants.FATAL_NORMAL_CASE), args[0])
+	}
+
+	count, err := checkSnapshot(path)
+	exitOnError(err)
+
+	var filename string = viper.GetString(constants.DATABASE_FILE)
+	if !yesNoPrompt(fmt.Sprintf("Replace database[%s] with snapshot[%s] containing %d entries?", filename, path, count)) {
+		log.Printf("Nothing restored.\n")
+		return
+	}
+
+	// Copy the snapshot first, since taking a snapshot of the current database
+	// may rotate out the very snapshot being restored.
+	temp, err := copySnapshot(path, filename)
+	exitOnError(err)
+
+	// Take a snapshot of the current database, so the restore itself can be
+	// undone by restoring that snapshot.  Unlike the other destructive
+	// commands, this does not depend on backup.before_destructive.
+	db, err := database.New(filename)
+	if err != nil {
+		os.Remove(temp)
+		exitOnError(err)
+	}
+
+	snapshot, err := createSnapshot(db)
+	db.Close()
+	if err != nil {
+		os.Remove(temp)
+		exitOnError(err)
+	}
+
+	log.Printf("%s database to [%s] before restore.\n", color.GreenString("Backed up"), snapshot)
+
+	// Anything left in the current database's write-ahead log must not be
+	// applied to the restored database.
+	os.Remove(filename + "-wal")
+	os.Remove(filename + "-shm")
+
+	// Renaming the copy over the database means it is never left half
+	// written.
+	err = os.Rename(temp, filename)
+	if err != nil {
+		os.Remove(temp)
+		exitOnError(err)
+	}
+
+	log.Printf("%s database[%s] from snapshot[%s].\n", color.GreenString("Restored"), filename, path)
+}
+
+// Make sure the snapshot is an intact Time Tracker database that this version
+// of Time Tracker understands.  Returns the number of entries in it.
+func checkSnapshot(path string) (int64, error) {
+	snapshot, err := database.OpenReadOnly(path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer snapshot.Close()
+
+	err = snapshot.IntegrityCheck()
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := snapshot.GetSchemaVersion()
+	if err != nil {
+		return 0, err
+	}
+
+	if version > database.LatestSchemaVersion() {
+		return 0, fmt.Errorf("snapshot[%s] is at schema version %d, which is newer than this version of Time Tracker supports (%d)", path, version, database.LatestSchemaVersion())
+	}
+
+	return snapshot.GetCountEntries()
+}
+
+// Copy the snapshot to a temporary file next to the database.  Returns the
+// name of the temporary file.
+func copySnapshot(path string, filename string) (string, error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return constants.EMPTY, err
+	}
+
+	defer source.Close()
+
+	temp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".restore-*")
+	if err != nil {
+		return constants.EMPTY, err
+	}
+
+	_, err = io.Copy(temp, source)
+	if err == nil {
+		err = temp.Sync()
+	}
+
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(temp.Name())
+		return constants.EMPTY, err
+	}
+
+	return temp.Name(), nil
+}
@@ -47,8 +47,19 @@ import (
 )
 
 var cfgFile string
+var inMemory bool
 var note string
 
+// newStore creates the Store the commands operate against.  Replace it to run
+// the commands against some other Store.
+var newStore = func() (database.Store, error) {
+	if inMemory {
+		return database.NewMemoryStore(), nil
+	}
+
+	return database.New(viper.GetString(constants.DATABASE_FILE))
+}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "timetracker",
@@ -96,6 +107,8 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", constants.EMPTY, "config file (default is $HOME/.timetracker.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profileName, constants.PROFILE, constants.EMPTY, "Use the specified profile instead of the active one")
+	rootCmd.PersistentFlags().BoolVar(&inMemory, constants.IN_MEMORY, false, "Use a throwaway in-memory store instead of the database file")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -133,6 +146,10 @@ func initConfig() {
 	// Set day of the week when determining start of the week.
 	viper.SetDefault("week_start", "Sunday")
 
+	// Wait up to 5 seconds for another process to finish writing to the
+	// database before giving up.
+	viper.SetDefault("busy_timeout", 5000)
+
 	// Set debug to false.
 	viper.SetDefault("debug", false)
 
@@ -143,6 +160,14 @@ func initConfig() {
 	// report.
 	viper.SetDefault("show_by_day_totals", true)
 
+	// Keep snapshots of the database in ~/.timetracker-backups, keeping the newest snapshot
+	// of each of the last 7 days and of each of the last 4 weeks, and take one
+	// before any destructive command.
+	viper.SetDefault("backup.directory", filepath.Join(home, ".timetracker-backups"))
+	viper.SetDefault("backup.keep_daily", 7)
+	viper.SetDefault("backup.keep_weekly", 4)
+	viper.SetDefault("backup.before_destructive", true)
+
 	// Set each of the reports to true.
 	viper.SetDefault("report.by_project", true)
 	viper.SetDefault("report.by_task", true)
@@ -163,8 +188,12 @@ func initConfig() {
 		}
 	}
 
+	// Switch to the active profile's database, favorites, and rounding.
+	applyProfile(home)
+
 	// Dump our some debug information.
 	if viper.GetBool("debug") {
+		log.Printf("%s = [%s]\n", constants.PROFILE, profileName)
 		log.Printf("%s = [%s]\n", constants.WEEK_START, viper.GetString(constants.WEEK_START))
 		log.Printf("%s = [%d]\n", constants.ROUND_TO_MINUTES, viper.GetInt64(constants.ROUND_TO_MINUTES))
 		log.Printf("%s = [%v]\n", constants.SPLIT_WORK_FROM_BREAK_TIME, viper.GetBool(constants.SPLIT_WORK_FROM_BREAK_TIME))
@@ -172,14 +201,51 @@ func initConfig() {
 
 	// Check if the database exists or not.  If it does not, create it.
 	_, err = os.Stat(viper.GetString(constants.DATABASE_FILE))
-	if errors.Is(err, os.ErrNotExist) {
+	if !inMemory && errors.Is(err, os.ErrNotExist) {
 		log.Printf("Database[%s] does not exist, creating...", viper.GetString(constants.DATABASE_FILE))
 
 		var filename string = viper.GetString(constants.DATABASE_FILE)
 		os.Create(filename)
 
-		db := database.New(viper.GetString(constants.DATABASE_FILE))
-		db.Create()
+		// Opening the database applies all the migrations, which creates the
+		// tables.
+		db, err := database.New(viper.GetString(constants.DATABASE_FILE))
+		exitOnError(err)
+		db.Close()
+	}
+}
+
+// Open the Store the commands operate against, exiting if it cannot be
+// opened.
+func openStore() database.Store {
+	store, err := newStore()
+	exitOnError(err)
+	return store
+}
+
+// If err is not nil, report it and exit with the exit code documented for its
+// kind of error.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+
+	log.Printf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
+	os.Exit(exitCode(err))
+}
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		return constants.EXIT_CODE_NOT_FOUND
+	case errors.Is(err, database.ErrConstraint):
+		return constants.EXIT_CODE_CONSTRAINT
+	case errors.Is(err, database.ErrLocked):
+		return constants.EXIT_CODE_LOCKED
+	case errors.Is(err, database.ErrCorrupt):
+		return constants.EXIT_CODE_CORRUPT
+	default:
+		return constants.EXIT_CODE_FAILURE
 	}
 }
 
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"log"
+	"strings"
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/agrison/go-commons-lang/stringUtils"
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// searchCmd represents the search command.
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Search the entries' projects, tasks, notes, and urls",
+	Long: `Search the entries' projects, tasks, notes, and urls using a full-text index.
+Every word in the query must match.  Use double quotes to search for a
+phrase, e.g. '"database migration"', a trailing '*' to search for a prefix,
+e.g. 'migrat*', and OR to match either of two words.  A word can be limited to
+one of the project, tasks, note, or url columns, e.g. 'note:migration'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSearch(cmd, args, openStore())
+	},
+}
+
+func init() {
+	searchCmd.Flags().StringP(constants.FROM, constants.EMPTY, constants.EMPTY, "Only search the entries on or after this date, in "+constants.DATE_FORMAT+" format.")
+	searchCmd.Flags().StringP(constants.TO, constants.EMPTY, constants.EMPTY, "Only search the entries on or before this date, in "+constants.DATE_FORMAT+" format.")
+	searchCmd.Flags().BoolP("no-rounding", constants.EMPTY, false, "Show all durations in their unrounded form.")
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string, store database.Store) {
+	fromStr, _ := cmd.Flags().GetString(constants.FROM)
+	toStr, _ := cmd.Flags().GetString(constants.TO)
+	noRounding, _ := cmd.Flags().GetBool("no-rounding")
+
+	var roundToMinutes int64 = 0
+	if !noRounding {
+		roundToMinutes = viper.GetInt64(constants.ROUND_TO_MINUTES)
+	}
+
+	var filter database.EntryFilter
+	if !stringUtils.IsEmpty(fromStr) {
+		filter.From = parseDateFlag(fromStr).StartOfDay().ToIso8601String()
+	}
+
+	if !stringUtils.IsEmpty(toStr) {
+		filter.To = parseDateFlag(toStr).EndOfDay().ToIso8601String()
+	}
+
+	var query string = strings.Join(args, " ")
+	hits, err := store.SearchEntries(query, filter)
+	exitOnError(err)
+
+	if len(hits) == 0 {
+		log.Printf("No entries match[%s].\n", query)
+		return
+	}
+
+	log.Println(renderByEntry(searchDurations(store, hits, roundToMinutes), hits, carbon.Local, roundToMinutes))
+	log.Printf("%d entries match[%s].\n", len(hits), query)
+}
+
+// The durations of the hits, as exportDurations works them out, but only
+// looking at the days with hits on them.
+func searchDurations(store database.Store, hits []models.Entry, roundToMinutes int64) map[int64]models.UID {
+	var days map[string]carbon.Carbon = make(map[string]carbon.Carbon)
+	for _, e := range hits {
+		var date carbon.Carbon = carbon.Parse(e.EntryDatetime)
+		days[date.Format(constants.CARBON_DATE_FORMAT)] = date
+	}
+
+	var durations map[int64]models.UID = make(map[int64]models.UID)
+	for _, date := range days {
+		entries, err := store.GetEntriesBetween(date.StartOfDay(), date.EndOfDay())
+		exitOnError(err)
+
+		for uid, duration := range calculateDurations(entries, carbon.Local, roundToMinutes) {
+			durations[uid] = duration
+		}
+	}
+
+	return durations
+}
@@ -16,7 +16,6 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"timetracker/internal/database"
-	"timetracker/internal/models"
 )
 
 // showCmd represents the show command
@@ -25,7 +24,7 @@ var showCmd = &cobra.Command{
 	Short: "Show various information",
 	Long:  "Show various information.",
 	Run: func(cmd *cobra.Command, args []string) {
-		runShow(cmd, args)
+		runShow(cmd, args, openStore())
 	},
 }
 
@@ -33,11 +32,13 @@ var favorites bool
 var statistics bool
 
 type Configuration struct {
-	DatabaseFilename string     `yaml:"database_file"`
-	WeekStart        string     `yaml:"week_start"`
-	RoundToMinutes   int        `yaml:"round_to_minutes"`
-	Debug            bool       `yaml:"debug"`
-	Favorites        []Favorite `yaml:"favorites"`
+	DatabaseFilename string             `yaml:"database_file"`
+	WeekStart        string             `yaml:"week_start"`
+	RoundToMinutes   int                `yaml:"round_to_minutes"`
+	Debug            bool               `yaml:"debug"`
+	Favorites        []Favorite         `yaml:"favorites"`
+	Profile          string             `yaml:"profile"`
+	Profiles         map[string]Profile `yaml:"profiles"`
 }
 
 type Favorite struct {
@@ -61,7 +62,7 @@ func init() {
 	// showCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
-func runShow(cmd *cobra.Command, _ []string) {
+func runShow(cmd *cobra.Command, _ []string, store database.Store) {
 	// Get the --favorites flag.
 	favorites, _ := cmd.Flags().GetBool(constants.FAVORITES)
 	statistics, _ := cmd.Flags().GetBool(constants.STATISTICS)
@@ -71,7 +72,7 @@ func runShow(cmd *cobra.Command, _ []string) {
 	}
 
 	if statistics {
-		showStatistics()
+		showStatistics(store)
 	}
 }
 
@@ -94,7 +95,7 @@ func showFavorites() {
 	log.Printf("Favorites found in configuration file[%s]:\n\n", viper.ConfigFileUsed())
 
 	var urlFound bool = false
-	for _, f := range config.Favorites {
+	for _, f := range config.activeFavorites() {
 		if len(f.URL) > 0 {
 			urlFound = true
 			break
@@ -108,7 +109,7 @@ func showFavorites() {
 		t.AppendHeader(table.Row{"#", "project+task"})
 	}
 
-	for i, f := range config.Favorites {
+	for i, f := range config.activeFavorites() {
 		if len(f.URL) > 0 {
 			t.AppendRow(table.Row{i, f.Favorite, f.URL})
 		} else {
@@ -119,11 +120,15 @@ func showFavorites() {
 	log.Println(t.Render())
 }
 
-func showStatistics() {
-	db := database.New(viper.GetString(constants.DATABASE_FILE))
-	var firstEntry models.Entry = db.GetFirstEntry()
-	var lastEntry models.Entry = db.GetLastEntry()
-	var count int64 = db.GetCountEntries()
+func showStatistics(store database.Store) {
+	firstEntry, err := store.GetFirstEntry()
+	exitOnError(err)
+
+	lastEntry, err := store.GetLastEntry()
+	exitOnError(err)
+
+	count, err := store.GetCountEntries()
+	exitOnError(err)
 
 	log.Printf("\n")
 
@@ -137,6 +142,6 @@ func showStatistics() {
 	t.AppendRow(table.Row{"First Entry", firstEntry.Dump(false)})
 	t.AppendRow(table.Row{"Last Entry", lastEntry.Dump(false)})
 	t.AppendRow(table.Row{"Total Records", count})
-	t.AppendRow(table.Row{"Total Duration", secondsToHuman(diff)})
+	t.AppendRow(table.Row{"Total Duration", secondsToHuman(diff, 0)})
 	log.Println(t.Render())
 }
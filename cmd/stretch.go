@@ -14,7 +14,6 @@ import (
 	"github.com/golang-module/carbon/v2"
 	"github.com/ijt/go-anytime"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 
 	"timetracker/internal/database"
 	"timetracker/internal/models"
@@ -26,7 +25,7 @@ var stretchCmd = &cobra.Command{
 	Short: "Stretch the latest entry",
 	Long:  "Stretch the latest entry to 'now' or the whatever is specified using the 'at' flag command.",
 	Run: func(cmd *cobra.Command, args []string) {
-		runStretch(cmd, args)
+		runStretch(cmd, args, openStore())
 	},
 }
 
@@ -35,7 +34,7 @@ func init() {
 	rootCmd.AddCommand(stretchCmd)
 }
 
-func runStretch(cmd *cobra.Command, _ []string) {
+func runStretch(cmd *cobra.Command, _ []string, store database.Store) {
 	// Get the current date/time.
 	var stretchTime carbon.Carbon = carbon.Now()
 
@@ -54,8 +53,8 @@ func runStretch(cmd *cobra.Command, _ []string) {
 	}
 
 	// Get the last Entry from the database.
-	db := database.New(viper.GetString(constants.DATABASE_FILE))
-	var entry models.Entry = db.GetLastEntry()
+	entry, err := store.GetLastEntry()
+	exitOnError(err)
 
 	// Create the prompt.
 	var prompt string = "Would you like to stretch\n" + entry.Dump(true)
@@ -68,7 +67,7 @@ func runStretch(cmd *cobra.Command, _ []string) {
 		var e models.Entry
 		e.Uid = entry.Uid
 		e.EntryDatetime = stretchTime.ToIso8601String()
-		db.UpdateEntry(e)
+		exitOnError(store.UpdateEntry(e, constants.STRETCH))
 
 		log.Printf("Last entry was stretched.\n")
 	} else {
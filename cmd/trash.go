@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/agrison/go-commons-lang/stringUtils"
+	"github.com/fatih/color"
+	"github.com/golang-module/carbon/v2"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// trashCmd represents the trash command.
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore, or empty deleted entries",
+	Long: `Entries removed by the delete and nuke commands are moved to the trash rather
+than being permanently deleted.  From the trash they can be listed, restored, or
+permanently deleted by emptying the trash.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// trashListCmd represents the trash list command.
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Args:  cobra.ExactArgs(0),
+	Short: "List the entries in the trash",
+	Long:  "List the entries in the trash along with when each was deleted.",
+	Run: func(cmd *cobra.Command, args []string) {
+		runTrashList(cmd, args, openStore())
+	},
+}
+
+// trashRestoreCmd represents the trash restore command.
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <uid|uid-range>...",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Restore entries from the trash",
+	Long: `Restore one or more entries from the trash, chosen by uid (e.g., 42) or by
+an inclusive range of uids (e.g., 40-45).  Restored entries keep their original
+uid, date/time, and properties.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTrashRestore(cmd, args, openStore())
+	},
+}
+
+// trashEmptyCmd represents the trash empty command.
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Args:  cobra.ExactArgs(0),
+	Short: "Permanently delete the entries in the trash",
+	Long: `Permanently delete the entries in the trash.  Use --older-than to only delete
+the entries that have been in the trash for at least the specified amount of
+time, e.g., 30d (days), 2w (weeks), or 36h (hours).`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runTrashEmpty(cmd, args, openStore())
+	},
+}
+
+func init() {
+	trashEmptyCmd.Flags().StringP(constants.OLDER_THAN, constants.EMPTY, constants.EMPTY, "Only delete the entries that have been in the trash longer than this, e.g., 30d.")
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	rootCmd.AddCommand(trashCmd)
+}
+
+func runTrashList(_ *cobra.Command, _ []string, store database.Store) {
+	trashed, err := store.GetTrashedEntries()
+	exitOnError(err)
+
+	if len(trashed) == 0 {
+		log.Printf("The trash is empty.\n")
+		return
+	}
+
+	log.Println(renderTrash(trashed))
+}
+
+func runTrashRestore(_ *cobra.Command, args []string, store database.Store) {
+	trashed, err := store.GetTrashedEntries()
+	exitOnError(err)
+
+	var chosen []models.TrashedEntry
+	for _, arg := range args {
+		low, high, err := parseUidRange(arg)
+		if err != nil {
+			log.Fatalf("%s: Invalid uid or uid range[%s].\n", color.RedString(constants.FATAL_NORMAL_CASE), arg)
+		}
+
+		var found bool = false
+		for _, t := range trashed {
+			if t.Uid >= low && t.Uid <= high {
+				chosen = append(chosen, t)
+				found = true
+			}
+		}
+
+		if !found {
+			log.Printf("%s: Nothing in the trash matches uid[%s].\n", color.YellowString("Warning"), arg)
+		}
+	}
+
+	if len(chosen) == 0 {
+		log.Printf("Nothing restored.\n")
+		return
+	}
+
+	var uids []int64 = make([]int64, 0, len(chosen))
+	for _, t := range chosen {
+		uids = append(uids, t.Uid)
+	}
+
+	count, err := store.RestoreFromTrash(uids)
+	exitOnError(err)
+
+	log.Println(renderTrash(chosen))
+	log.Printf("%s %d entries.\n", color.GreenString("Restored"), count)
+}
+
+func runTrashEmpty(cmd *cobra.Command, _ []string, store database.Store) {
+	olderThan, _ := cmd.Flags().GetString(constants.OLDER_THAN)
+
+	var deletedBefore carbon.Carbon = carbon.Now()
+	var label string = "Are you sure you want to permanently delete ALL the entries in the trash?"
+	if !stringUtils.IsEmpty(olderThan) {
+		age, err := parseAge(olderThan)
+		if err != nil {
+			log.Fatalf("%s: Invalid age[%s].  Please use a value like 30d, 2w, or 36h.\n", color.RedString(constants.FATAL_NORMAL_CASE), olderThan)
+		}
+
+		deletedBefore = carbon.CreateFromStdTime(time.Now().Add(-age))
+		label = fmt.Sprintf("Are you sure you want to permanently delete the entries deleted before %s?", deletedBefore.ToDateTimeString())
+	}
+
+	if yesNoPrompt(label) {
+		backupBeforeDestructive(store, "emptying the trash")
+
+		count, err := store.EmptyTrash(deletedBefore)
+		exitOnError(err)
+
+		log.Printf("%s %d entries.\n", color.GreenString(constants.DELETED), count)
+	} else {
+		log.Printf("Nothing deleted.\n")
+	}
+}
+
+func renderTrash(trashed []models.TrashedEntry) string {
+	var t table.Writer = table.NewWriter()
+	t.AppendHeader(table.Row{"Uid", constants.PROJECT_NORMAL_CASE, constants.TASKS_NORMAL_CASE, constants.NOTE_NORMAL_CASE, constants.DATE_TIME_NORMAL_CASE, constants.DELETED})
+	for _, e := range trashed {
+		var deleted string = carbon.Parse(e.DeletedDatetime).SetTimezone(carbon.Local).ToDateTimeString()
+		t.AppendRow(table.Row{e.Uid, e.Project, e.GetTasksAsString(), e.Note, e.EntryDatetime, deleted})
+	}
+
+	return t.Render()
+}
+
+// Parse either a single uid, e.g. "42", or an inclusive range of uids, e.g.
+// "40-45".
+func parseUidRange(s string) (int64, int64, error) {
+	lowStr, highStr, isRange := strings.Cut(s, "-")
+
+	low, err := strconv.ParseInt(strings.TrimSpace(lowStr), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !isRange {
+		return low, low, nil
+	}
+
+	high, err := strconv.ParseInt(strings.TrimSpace(highStr), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if high < low {
+		return 0, 0, fmt.Errorf("invalid range %d-%d", low, high)
+	}
+
+	return low, high, nil
+}
+
+// Parse an age such as "30d" or "2w".  Anything else is parsed as a Go
+// duration, e.g. "36h".
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		return time.ParseDuration(s)
+	}
+
+	count, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+
+	return time.Duration(count) * unit, nil
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"timetracker/constants"
+	"timetracker/internal/database"
+	"timetracker/internal/models"
+
+	"github.com/fatih/color"
+	"github.com/golang-module/carbon/v2"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// undoCmd represents the undo command.
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Args:  cobra.ExactArgs(0),
+	Short: "Undo the last add, break, hello, amend, stretch, delete, or nuke",
+	Long: `Every add, break, hello, amend, stretch, delete, and nuke is recorded in a
+journal kept in the database.  Undo reverts the most recent of those that has
+not already been undone.  Undoing an add, break, or hello moves the entry to
+the trash; undoing a delete or nuke restores the entries from the trash.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runUndo(cmd, args, openStore())
+	},
+}
+
+// redoCmd represents the redo command.
+var redoCmd = &cobra.Command{
+	Use:   "redo",
+	Args:  cobra.ExactArgs(0),
+	Short: "Redo the last undone command",
+	Long: `Reapply the most recently undone command.  Once a new add, break, hello,
+amend, stretch, delete, or nuke is performed, the undone commands can no longer
+be redone.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runRedo(cmd, args, openStore())
+	},
+}
+
+func init() {
+	undoCmd.Flags().BoolP(constants.LIST, constants.EMPTY, false, "List the commands that can be undone, most recent first, without undoing anything.")
+	redoCmd.Flags().BoolP(constants.LIST, constants.EMPTY, false, "List the commands that can be redone, next first, without redoing anything.")
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+}
+
+func runUndo(cmd *cobra.Command, _ []string, store database.Store) {
+	list, _ := cmd.Flags().GetBool(constants.LIST)
+	if list {
+		listOperations(store, false)
+		return
+	}
+
+	operation, err := store.Undo()
+	exitOnError(err)
+
+	log.Printf("%s %s.\n", color.GreenString("Undid"), describeOperation(operation))
+}
+
+func runRedo(cmd *cobra.Command, _ []string, store database.Store) {
+	list, _ := cmd.Flags().GetBool(constants.LIST)
+	if list {
+		listOperations(store, true)
+		return
+	}
+
+	operation, err := store.Redo()
+	exitOnError(err)
+
+	log.Printf("%s %s.\n", color.GreenString("Redid"), describeOperation(operation))
+}
+
+func listOperations(store database.Store, undone bool) {
+	operations, err := store.GetOperations(undone)
+	exitOnError(err)
+
+	if len(operations) == 0 {
+		if undone {
+			log.Printf("Nothing to redo.\n")
+		} else {
+			log.Printf("Nothing to undo.\n")
+		}
+
+		return
+	}
+
+	var t table.Writer = table.NewWriter()
+	t.SetAutoIndex(true)
+	t.AppendHeader(table.Row{constants.DATE_TIME_NORMAL_CASE, "Command", "What"})
+	for _, o := range operations {
+		var when string = carbon.Parse(o.OperationDatetime).SetTimezone(carbon.Local).ToDateTimeString()
+		t.AppendRow(table.Row{when, o.Command, describeOperation(o)})
+	}
+
+	log.Println(t.Render())
+}
+
+// Describe the operation, e.g. "amend of entry 42 (task: meeting -> email)".
+func describeOperation(o models.Operation) string {
+	if o.Action == constants.ACTION_UPDATE {
+		var uid int64
+		var changes []string
+		for _, c := range o.Changes {
+			uid = c.EntryUid
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", c.Field, c.OldValue, c.NewValue))
+		}
+
+		return fmt.Sprintf("%s of entry %d (%s)", o.Command, uid, strings.Join(changes, ", "))
+	}
+
+	if len(o.EntryUids) == 1 {
+		return fmt.Sprintf("%s of entry %d", o.Command, o.EntryUids[0])
+	}
+
+	// Nuking can journal thousands of entries, so only list a few of them.
+	var uids []string
+	for i, uid := range o.EntryUids {
+		if i == 5 {
+			uids = append(uids, "...")
+			break
+		}
+
+		uids = append(uids, fmt.Sprint(uid))
+	}
+
+	return fmt.Sprintf("%s of %d entries (%s)", o.Command, len(o.EntryUids), strings.Join(uids, ", "))
+}
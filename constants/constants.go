@@ -1,29 +1,68 @@
 package constants
 
+const ACTION_DELETE string = "delete"
+const ACTION_INSERT string = "insert"
+const ACTION_UPDATE string = "update"
 const ADDING string = "Adding"
 const ALL string = "all"
+const AMEND string = "amend"
 const AMENDING string = "Amending"
+const APPLIED string = "Applied"
 const APPLICATION_NAME = "Time Tracker"
 const AT string = "at"
+const BACKUP_BEFORE_DESTRUCTIVE string = "backup.before_destructive"
+const BACKUP_DIRECTORY string = "backup.directory"
+const BACKUP_KEEP_DAILY string = "backup.keep_daily"
+const BACKUP_KEEP_WEEKLY string = "backup.keep_weekly"
+const BEFORE string = "before"
 const BREAK string = "***break"
+const BUSY_TIMEOUT string = "busy_timeout"
 const CARBON_DATE_FORMAT string = "Y-m-d"
 const CARBON_START_END_TIME_FORMAT string = "h:ia"
+const COLUMNS string = "columns"
 const CONFIGURATION_FILE string = ".timetracker.yaml"
 const DATABASE_FILE string = "database_file"
+const DATABASE_FILE_FLAG string = "database-file"
 const DATE_FORMAT string = "2006-01-02" // WTF golang?  Why this date format?
 const DATE_NORMAL_CASE = "Date"
 const DATE_TIME_NORMAL_CASE = "Date Time"
+const DAY string = "day"
+const DAY_FIRST string = "day-first"
+const DEFAULT_PROFILE string = "default"
+const DELETE string = "delete"
+const DELETED string = "Deleted"
+const DELIMITER string = "delimiter"
+const DOCTOR string = "doctor"
 const DURATION_NORMAL_CASE = "Duration"
 const DRY_RUN = "dry-run"
 const EMPTY string = ""
+const ENTRY_DATETIME string = "entry_datetime"
+const EXIT_CODE_CONSTRAINT int = 3
+const EXIT_CODE_CORRUPT int = 5
+const EXIT_CODE_FAILURE int = 1
+const EXIT_CODE_LOCKED int = 4
+const EXIT_CODE_NOT_FOUND int = 2
+const EXPORT string = "export"
 const FATAL_NORMAL_CASE string = "Fatal"
 const FAVORITE string = "favorite"
 const FAVORITES string = "favorites"
+const FIX string = "fix"
+const FORMAT string = "format"
+const FROM string = "from"
 const HELLO string = "***hello"
+const IMPORT string = "import"
+const INCLUDE_ARCHIVES string = "include-archives"
+const INCLUDE_BREAKS string = "include-breaks"
+const IN_MEMORY string = "in-memory"
+const LAST string = "last"
+const LIST string = "list"
 const NATURAL_LANGUAGE_DESCRIPTION string = "Natural Language Time, e.g., '18 minutes ago'"
 const NOTE string = "note"
 const NOTE_DESCRIPTION string = "A note associated with this entry"
 const NOTE_NORMAL_CASE = "Note"
+const NO_HEADER string = "no-header"
+const NUKE string = "nuke"
+const OLDER_THAN string = "older-than"
 const PRINT_DATE_WIDTH int = 10
 const PRINT_DURATION_WIDTH int = 38
 const PRINT_NOTE_WIDTH int = 40
@@ -31,9 +70,12 @@ const PRINT_PROJECT_WIDTH int = 20
 const PRINT_START_END_WIDTH int = 20
 const PRINT_TASK_WIDTH int = 20
 const PRIOR_YEARS string = "prior-years"
+const PROFILE string = "profile"
+const PROFILES string = "profiles"
 const PROJECT string = "project"
 const PROJECT_NORMAL_CASE = "Project"
 const PROJECTS_NORMAL_CASE = "Project(s)"
+const REDO string = "redo"
 const REPORT_BY_DAY = "report.by_day"
 const REPORT_BY_DAY_FORMAT string = "%-10s  %-38s  %-20s  %-20s"
 const REPORT_BY_ENTRY = "report.by_entry"
@@ -42,19 +84,31 @@ const REPORT_BY_PROJECT = "report.by_project"
 const REPORT_BY_PROJECT_FORMAT string = "%-38s  %-20s  %-20s"
 const REPORT_BY_TASK = "report.by_task"
 const REPORT_CARBON_TO_FROM_FORMAT string = "Y-M-d"
+const ROUND string = "round"
 const ROUND_TO_MINUTES string = "round_to_minutes"
+const ROUND_TO_MINUTES_FLAG string = "round-to-minutes"
 const SECONDS_PER_DAY = 86400
 const SHOW_BY_DAY_TOTALS string = "show_by_day_totals"
+const SINCE string = "since"
 const SPLIT_WORK_FROM_BREAK_TIME string = "split_work_from_break_time"
 const START_END_NORMAL_CASE = "Start-End"
 const STATISTICS string = "statistics"
+const STATUS string = "status"
+const STRETCH string = "stretch"
 const TASK string = "task"
 const TASK_DELIMITER string = "+"
 const TASK_NORMAL_CASE = "Task"
 const TASKS_NORMAL_CASE = "Task(s)"
+const TIMEZONE string = "timezone"
+const TO string = "to"
+const TODAY string = "today"
 const TOTAL = "TOTAL"
+const TZ string = "tz"
+const UNDO string = "undo"
 const UNKNOWN_UID int64 = -1
 const URL = "url"
 const URL_NORMAL_CASE = "URL"
+const VACUUM string = "vacuum"
 const WEB_SITE string = "https://github.com/jlanzarotta/timetracker/"
 const WEEK_START string = "week_start"
+const WITHIN string = "within"
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+// The year an entry was recorded in, in the time zone it was recorded in.
+const recordedYear string = "strftime('%Y', entry_datetime, COALESCE(entry_offset, '+00:00'))"
+
+// Get the number of entries before the specified date/time for each year,
+// using the year the entry was recorded in.
+func (db *Database) GetCountEntriesByYearBefore(before carbon.Carbon) (map[string]int64, error) {
+	results, err := db.Conn.QueryContext(db.Context, "SELECT "+recordedYear+", COUNT(*) FROM entry WHERE entry_datetime < ? GROUP BY 1 ORDER BY 1;", utcOf(before))
+	if err != nil {
+		return nil, wrapError("Error trying to retrieve count of entries by year", err)
+	}
+
+	defer results.Close()
+
+	counts := make(map[string]int64)
+	for results.Next() {
+		var year string
+		var count int64
+		err = results.Scan(&year, &count)
+		if err != nil {
+			return nil, wrapError("Error trying to Scan count of entries by year into data structure", err)
+		}
+
+		counts[year] = count
+	}
+
+	return counts, wrapError("Error trying to retrieve count of entries by year", results.Err())
+}
+
+// Move the entries, and their properties, recorded in the specified year
+// before the specified date/time into the archive database filename, creating
+// it if need be.  The entries are only removed from this database once the
+// number of entries and properties in the archive has been verified.  The
+// journaled operations on the archived entries can no longer be undone or
+// redone, so they are removed from the journal.  Returns the number of entries
+// archived.
+func (db *Database) ArchiveYear(year string, before carbon.Carbon, filename string) (int64, error) {
+	// Opening the archive creates it, if need be, and brings its schema up to
+	// date.
+	archive, err := New(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	archive.Close()
+
+	// ATTACH only applies to a single connection, so hold on to one.
+	conn, err := db.Conn.Conn(db.Context)
+	if err != nil {
+		return 0, wrapError("Error trying to get database connection", err)
+	}
+
+	defer conn.Close()
+
+	_, err = conn.ExecContext(db.Context, "ATTACH DATABASE ? AS archive;", filename)
+	if err != nil {
+		return 0, wrapError("Error trying to attach archive database "+filename, err)
+	}
+
+	defer conn.ExecContext(db.Context, "DETACH DATABASE archive;")
+
+	tx, err := conn.BeginTx(db.Context, nil)
+	if err != nil {
+		return 0, wrapError("Error trying to begin transaction", err)
+	}
+
+	var where string = recordedYear + " = ? AND entry_datetime < ?"
+	var args []any = []any{year, utcOf(before)}
+
+	var statements []string = []string{
+		"INSERT INTO archive.entry (uid, project, note, entry_datetime, entry_offset) SELECT uid, project, note, entry_datetime, entry_offset FROM main.entry WHERE " + where + ";",
+		"INSERT INTO archive.property (entry_uid, name, value) SELECT entry_uid, name, value FROM main.property WHERE entry_uid IN (SELECT uid FROM main.entry WHERE " + where + ") ORDER BY rowid;",
+	}
+
+	for _, statement := range statements {
+		_, err = tx.ExecContext(db.Context, statement, args...)
+		if err != nil {
+			tx.Rollback()
+			return 0, wrapError("Error trying to copy entries to archive database "+filename, err)
+		}
+	}
+
+	// Verify everything made it into the archive before removing anything.
+	var verify = func(table string, column string) error {
+		var expected, actual int64
+		err := tx.QueryRowContext(db.Context, "SELECT COUNT(*) FROM main."+table+" WHERE "+column+" IN (SELECT uid FROM main.entry WHERE "+where+");", args...).Scan(&expected)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(db.Context, "SELECT COUNT(*) FROM archive."+table+" WHERE "+column+" IN (SELECT uid FROM main.entry WHERE "+where+");", args...).Scan(&actual)
+		if err != nil {
+			return err
+		}
+
+		if expected != actual {
+			return fmt.Errorf("expected %d %s records in the archive, found %d", expected, table, actual)
+		}
+
+		return nil
+	}
+
+	err = verify("entry", "uid")
+	if err == nil {
+		err = verify("property", "entry_uid")
+	}
+
+	if err != nil {
+		tx.Rollback()
+		return 0, wrapError("Error trying to verify archive database "+filename, err)
+	}
+
+	// Archiving cannot be undone, and neither can the operations on the
+	// archived entries once they are gone, so forget them.  Their
+	// journal_entry records are deleted via 'ON DELETE CASCADE'.
+	_, err = tx.ExecContext(db.Context, "DELETE FROM main.journal WHERE uid IN (SELECT journal_uid FROM main.journal_entry WHERE entry_uid IN (SELECT uid FROM main.entry WHERE "+where+"));", args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, wrapError("Error trying to delete the journal records of archived entries", err)
+	}
+
+	// Their properties go with them, the same as when they are trashed.
+	result, err := tx.ExecContext(db.Context, "DELETE FROM main.entry WHERE "+where+";", args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, wrapError("Error trying to delete archived entries", err)
+	}
+
+	count, _ := result.RowsAffected()
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, wrapError("Error committing transaction", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"errors"
+	"strings"
+)
+
+// Write a consistent snapshot of the database to filename, which must not
+// already exist.  The database stays online while the snapshot is taken.
+func (db *Database) Backup(filename string) error {
+	_, err := db.Conn.ExecContext(db.Context, "VACUUM INTO ?;", filename)
+	return wrapError("Error trying to back up database to "+filename, err)
+}
+
+// Check the integrity of the database, returning an ErrCorrupt error listing
+// the problems found, if any.
+func (db *Database) IntegrityCheck() error {
+	results, err := db.Conn.QueryContext(db.Context, "PRAGMA integrity_check;")
+	if err != nil {
+		return wrapError("Error trying to check database integrity", err)
+	}
+
+	defer results.Close()
+
+	var problems []string
+	for results.Next() {
+		var problem string
+		err = results.Scan(&problem)
+		if err != nil {
+			return wrapError("Error trying to Scan integrity check results into data structure", err)
+		}
+
+		if problem != "ok" {
+			problems = append(problems, problem)
+		}
+	}
+
+	err = results.Err()
+	if err != nil {
+		return wrapError("Error trying to check database integrity", err)
+	}
+
+	if len(problems) > 0 {
+		return &Error{Op: "Database failed its integrity check", Kind: ErrCorrupt, Err: errors.New(strings.Join(problems, "; "))}
+	}
+
+	return nil
+}
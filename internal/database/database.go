@@ -5,13 +5,11 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
 	"timetracker/constants"
 	"timetracker/internal/models"
 
-	"github.com/fatih/color"
 	"github.com/golang-module/carbon/v2"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/viper"
@@ -23,12 +21,19 @@ type Database struct {
 	Context  context.Context
 }
 
-func New(filename string) *Database {
+// Open a connection to the database without applying any pending migrations.
+func Open(filename string) (*Database, error) {
 	// NOTE: Make sure '_foreign_keys=on' is set or 'DELETE ON CASCADE' will not work.
-	conn, err := sql.Open("sqlite3", filename+"?_loc=UTC&_foreign_keys=on")
+	//
+	// WAL journaling lets readers, like a status-line script, keep reading
+	// while an entry is being written.  Rather than failing straight away with
+	// "database is locked", wait up to busy_timeout milliseconds for another
+	// writer to finish.  Transactions take the write lock up front, since
+	// SQLite cannot wait when upgrading a read lock to a write lock.
+	var dsn string = fmt.Sprintf("%s?_loc=UTC&_foreign_keys=on&_journal_mode=WAL&_busy_timeout=%d&_txlock=immediate", filename, viper.GetInt(constants.BUSY_TIMEOUT))
+	conn, err := sql.Open("sqlite3", dsn)
 	if err != nil {
-		log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return nil, wrapError("Error trying to open database", err)
 	}
 
 	db := Database{}
@@ -39,466 +44,612 @@ func New(filename string) *Database {
 	// Ping the database to ensure we are connected.
 	err = db.Conn.Ping()
 	if err != nil {
-		log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		conn.Close()
+		return nil, wrapError("Error trying to connect to database", err)
 	}
 
-	return &db
+	return &db, nil
 }
 
-func (db *Database) Close() {
-	db.Conn.Close()
+// Open a read-only connection to the database, e.g. a snapshot, that never
+// writes to it, not even to change its journal mode or to leave a write-ahead
+// log behind.  The database is treated as immutable, so only the database file
+// itself is read, even if a write-ahead log is sitting next to it.
+func OpenReadOnly(filename string) (*Database, error) {
+	conn, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro&immutable=1&_loc=UTC", filename))
+	if err != nil {
+		return nil, wrapError("Error trying to open database", err)
+	}
+
+	db := Database{}
+	db.Filename = filename
+	db.Conn = conn
+	db.Context = context.Background()
+
+	// Ping the database to ensure we are connected.
+	err = db.Conn.Ping()
+	if err != nil {
+		conn.Close()
+		return nil, wrapError("Error trying to connect to database", err)
+	}
+
+	return &db, nil
 }
 
-func (db *Database) Create() {
-	// Create the entry table.
-	query := "CREATE TABLE entry (uid INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, project TEXT(128) NOT NULL, note TEXT(128), entry_datetime TEXT NOT NULL);"
-	_, err := db.Conn.Exec(query)
+// Open a connection to the database and bring its schema up to date.
+func New(filename string) (*Database, error) {
+	db, err := Open(filename)
 	if err != nil {
-		log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return nil, err
 	}
 
-	// Create the property table.
-	query = "CREATE TABLE property (entry_uid INTEGER NOT NULL, name TEXT(128) NOT NULL, value TEXT(128) NOT NULL, CONSTRAINT property_FK FOREIGN KEY (entry_uid) REFERENCES entry(uid) ON DELETE CASCADE);"
-	_, err = db.Conn.Exec(query)
+	_, err = db.Migrate()
 	if err != nil {
-		log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		db.Close()
+		return nil, err
 	}
+
+	return db, nil
+}
+
+func (db *Database) Close() error {
+	return db.Conn.Close()
+}
+
+// Insert a new Entry, along with its properties, journaling it as performed
+// by the specified command.
+func (db *Database) InsertNewEntry(entry models.Entry, command string) error {
+	return retryIfBusy(func() error {
+		_, err := db.insertNewEntries([]models.Entry{entry}, command, false)
+		return err
+	})
+}
+
+// Insert the new Entries, along with their properties, in a single
+// transaction, journaling them as a single operation performed by the
+// specified command so they can be undone together.  Returns the number of
+// entries inserted.
+func (db *Database) InsertNewEntries(entries []models.Entry, command string) (int64, error) {
+	var count int64
+	err := retryIfBusy(func() error {
+		var err error
+		count, err = db.insertNewEntries(entries, command, false)
+		return err
+	})
+
+	return count, err
+}
+
+// Insert the Entries the same way InsertNewEntries does, except each Entry
+// keeps its uid unless the uid is unknown or already used by an Entry, or by an
+// Entry in the trash.
+func (db *Database) ImportEntries(entries []models.Entry, command string) (int64, error) {
+	var count int64
+	err := retryIfBusy(func() error {
+		var err error
+		count, err = db.insertNewEntries(entries, command, true)
+		return err
+	})
+
+	return count, err
 }
 
-func (db *Database) InsertNewEntry(entry models.Entry) {
+func (db *Database) insertNewEntries(entries []models.Entry, command string, keepUids bool) (int64, error) {
 	tx, err := db.Conn.BeginTx(db.Context, &sql.TxOptions{Isolation: sql.LevelSerializable})
 	if err != nil {
-		log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return 0, wrapError("Error trying to begin transaction", err)
 	}
 
-	result, err := tx.ExecContext(db.Context, "INSERT INTO entry (uid, project, note, entry_datetime) VALUES (?, ?, ?, ?);", nil, entry.Project, entry.Note, entry.EntryDatetime)
+	journalUid, err := db.startOperation(tx, command, constants.ACTION_INSERT)
 	if err != nil {
-		rollBackError := tx.Rollback()
-		if rollBackError != nil {
-			log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), rollBackError.Error())
-			os.Exit(1)
-		}
-
-		log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		tx.Rollback()
+		return 0, wrapError("Error trying to journal inserted entry", err)
 	}
 
-	// Now that the record was inserted, get the last inserted id... in our case it it the UID.
-	uid, err := result.LastInsertId()
-	if err != nil {
-		log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+	// Insert the entries keeping their uids first, so the entries whose uids
+	// are already in use cannot take the uid of one of them.
+	if keepUids {
+		entries, err = db.keptUidsFirst(tx, entries)
+		if err != nil {
+			tx.Rollback()
+			return 0, wrapError("Error trying to check entry uids", err)
+		}
 	}
 
-	// Now insert each of the properties for this entry.
-	for _, v := range entry.Properties {
-		_, err := tx.ExecContext(db.Context, "INSERT INTO property (entry_uid, name, value) VALUES (?, ?, ?);", uid, v.Name, v.Value)
+	for _, entry := range entries {
+		var uid int64 = constants.UNKNOWN_UID
+		if keepUids {
+			uid = entry.Uid
+		}
+
+		// Never reuse the uid of an entry sitting in the trash, otherwise it
+		// could not be restored, or of an entry moved to an archive, whose
+		// uid is remembered in sqlite_sequence, otherwise reports including
+		// the archives would mix the two up.  Each MAX() is taken on its own
+		// so SQLite can read it straight off the primary key instead of
+		// scanning both tables for every entry.
+		entryDatetime, entryOffset := toUtc(entry.EntryDatetime)
+		result, err := tx.ExecContext(db.Context, `INSERT INTO entry (uid, project, note, entry_datetime, entry_offset) VALUES (
+			CASE WHEN ?1 > 0 AND NOT EXISTS (SELECT 1 FROM entry WHERE uid = ?1) AND NOT EXISTS (SELECT 1 FROM trash_entry WHERE uid = ?1) THEN ?1
+			ELSE MAX(COALESCE((SELECT MAX(uid) FROM entry), 0), COALESCE((SELECT MAX(uid) FROM trash_entry), 0), COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'entry'), 0)) + 1 END,
+			?2, ?3, ?4, ?5);`, uid, entry.Project, entry.Note, entryDatetime, entryOffset)
+		if err != nil {
+			tx.Rollback()
+			return 0, wrapError("Error trying to insert entry", err)
+		}
+
+		// Now that the record was inserted, get the last inserted id... in our case it it the UID.
+		uid, err = result.LastInsertId()
 		if err != nil {
-			rollBackError := tx.Rollback()
-			if rollBackError != nil {
-				log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), rollBackError.Error())
-				os.Exit(1)
+			tx.Rollback()
+			return 0, wrapError("Error trying to retrieve inserted entry's uid", err)
+		}
+
+		// Now insert each of the properties for this entry.
+		for _, v := range entry.Properties {
+			_, err := tx.ExecContext(db.Context, "INSERT INTO property (entry_uid, name, value) VALUES (?, ?, ?);", uid, v.Name, v.Value)
+			if err != nil {
+				tx.Rollback()
+				return 0, wrapError("Error trying to insert property", err)
 			}
+		}
 
-			log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+		err = db.journalEntry(tx, journalUid, uid)
+		if err != nil {
+			tx.Rollback()
+			return 0, wrapError("Error trying to journal inserted entry", err)
 		}
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return 0, wrapError("Error committing transaction", err)
 	}
-}
 
-func (db *Database) GetDistinctUIDs(start carbon.Carbon, end carbon.Carbon) []DistinctUID {
-	results, err := db.Conn.Query(`
-		SELECT DISTINCT
-			e.uid, e.project, e.entry_datetime
-		FROM entry e
-		WHERE e.entry_datetime BETWEEN ? AND ?
-		ORDER BY entry_datetime;
-		`, start.ToIso8601String(), end.ToIso8601String(),
-	)
-
-	if err != nil {
-		log.Fatalf("%s: Error trying to retrieve distinct uids. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
-	}
+	return int64(len(entries)), nil
+}
 
-	records := []DistinctUID{}
-	for results.Next() {
-		var distinctUID DistinctUID
-		err = results.Scan(&distinctUID.Uid, &distinctUID.Project, &distinctUID.EntryDatetime)
+// Reorder the entries so those whose uids are not in use come first.
+func (db *Database) keptUidsFirst(tx *sql.Tx, entries []models.Entry) ([]models.Entry, error) {
+	var kept []models.Entry
+	var others []models.Entry
+	for _, entry := range entries {
+		var inUse bool
+		err := tx.QueryRowContext(db.Context, "SELECT EXISTS (SELECT 1 FROM entry WHERE uid = ?1 UNION ALL SELECT 1 FROM trash_entry WHERE uid = ?1);", entry.Uid).Scan(&inUse)
 		if err != nil {
-			log.Fatalf("%s: Error trying to scan results into DistinctUID data structure. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+			return nil, err
 		}
 
-		records = append(records, distinctUID)
+		if entry.Uid > 0 && !inUse {
+			kept = append(kept, entry)
+		} else {
+			others = append(others, entry)
+		}
 	}
 
-	return records
+	return append(kept, others...), nil
 }
 
-func (db *Database) GetProperties(entryUid int64) []Property {
-	var s string = fmt.Sprintf("SELECT p.name, p.value FROM property p WHERE p.entry_uid = %d;", entryUid)
-
-	results, err := db.Conn.Query(s)
+func (db *Database) GetProperties(entryUid int64) ([]Property, error) {
+	results, err := db.Conn.QueryContext(db.Context, "SELECT p.name, p.value FROM property p WHERE p.entry_uid = ?;", entryUid)
 	if err != nil {
-		log.Fatalf("%s: Error trying to retrieve Property records. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return nil, wrapError("Error trying to retrieve Property records", err)
 	}
 
+	defer results.Close()
+
 	records := []Property{}
 	for results.Next() {
 		var property Property
 		err = results.Scan(&property.Name, &property.Value)
 		if err != nil {
-			log.Fatalf("%s: Error trying to Scan Property results into data structure. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+			return nil, wrapError("Error trying to Scan Property results into data structure", err)
 		}
 
 		records = append(records, property)
 	}
 
-	return records
+	return records, wrapError("Error trying to retrieve Property records", results.Err())
 }
 
-func (db *Database) GetEntries(in string) []models.Entry {
-	var s string = fmt.Sprintf("SELECT e.uid, e.project, e.note, e.entry_datetime FROM entry e WHERE e.uid IN (%s) ORDER BY entry_datetime;", in)
+// Get the Entries, with their properties, between start and end using a
+// single query over the entry_datetime index.
+func (db *Database) GetEntriesBetween(start carbon.Carbon, end carbon.Carbon) ([]models.Entry, error) {
+	return db.queryEntries("e.entry_datetime BETWEEN ? AND ?", utcOf(start), utcOf(end))
+}
+
+// Get the Entries, with their properties, matching the where clause ordered by
+// date/time.
+func (db *Database) queryEntries(where string, args ...any) ([]models.Entry, error) {
+	results, err := db.Conn.QueryContext(db.Context, `
+		SELECT
+			e.uid, e.project, e.note, e.entry_datetime, e.entry_offset, p.name, p.value
+		FROM entry e
+		LEFT JOIN property p ON p.entry_uid = e.uid
+		WHERE `+where+`
+		ORDER BY e.entry_datetime, e.uid, p.rowid;
+		`, args...,
+	)
 
-	results, err := db.Conn.Query(s)
 	if err != nil {
-		log.Fatalf("%s: Error trying to retrieve Entry records. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return nil, wrapError("Error trying to retrieve Entry records", err)
 	}
 
-	records := []models.Entry{}
+	defer results.Close()
+
+	entries := []models.Entry{}
 	for results.Next() {
-		var entry models.Entry
-		err = results.Scan(&entry.Uid, &entry.Project, &entry.Note, &entry.EntryDatetime)
+		var entry Entry
+		var property Property
+		err = results.Scan(&entry.Uid, &entry.Project, &entry.Note, &entry.EntryDatetime, &entry.EntryOffset, &property.Name, &property.Value)
 		if err != nil {
-			log.Fatalf("%s: Error trying to Scan Entries results into data structure. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+			return nil, wrapError("Error trying to Scan Entries results into data structure", err)
 		}
 
-		var properties []Property = db.GetProperties(entry.Uid)
-		for _, p := range properties {
-			entry.AddEntryProperty(p.Name.String, p.Value.String)
-		}
+		var last *models.Entry
+		entries, last = groupRow(entries, entry.Uid, func(e models.Entry) int64 { return e.Uid }, func() models.Entry {
+			return models.NewEntry(entry.Uid, entry.Project, entry.Note.String, entry.recordedDatetime())
+		})
 
-		records = append(records, entry)
+		if property.Name.Valid {
+			last.AddEntryProperty(property.Name.String, property.Value.String)
+		}
 	}
 
-	return records
+	return entries, wrapError("Error trying to retrieve Entry records", results.Err())
 }
 
-func (db *Database) GetEntriesForToday(start carbon.Carbon, end carbon.Carbon) []models.Entry {
-	var s string = fmt.Sprintf("SELECT e.uid, e.project, e.note, e.entry_datetime FROM entry e WHERE e.entry_datetime between '%s' AND '%s' ORDER BY entry_datetime;", start.ToIso8601String(), end.ToIso8601String())
+// Joining a record to its details, e.g. an entry to its properties, returns a
+// row for each detail, so a new record, from newRecord, is only started when
+// the row's uid differs from the last record's.  Returns the records and the
+// one the row belongs to.
+func groupRow[T any](records []T, uid int64, uidOf func(T) int64, newRecord func() T) ([]T, *T) {
+	if len(records) == 0 || uidOf(records[len(records)-1]) != uid {
+		records = append(records, newRecord())
+	}
+
+	return records, &records[len(records)-1]
+}
 
-	results, err := db.Conn.Query(s)
+func (db *Database) GetEntry(uid int64) (models.Entry, error) {
+	var e Entry
+	err := db.Conn.QueryRowContext(db.Context, "SELECT e.uid, e.project, e.note, e.entry_datetime, e.entry_offset FROM entry e WHERE e.uid = ?;", uid).Scan(&e.Uid, &e.Project, &e.Note, &e.EntryDatetime, &e.EntryOffset)
 	if err != nil {
-		log.Fatalf("%s: Error trying to retrieve Entry records. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return models.Entry{}, wrapError("Error trying to retrieve Uid's Entry record", err)
 	}
 
-	records := []Entry{}
-	for results.Next() {
-		var entry Entry
-		err = results.Scan(&entry.Uid, &entry.Project, &entry.Note, &entry.EntryDatetime)
-		if err != nil {
-			log.Fatalf("%s: Error trying to Scan Entries results into data structure. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
-		}
+	var entry models.Entry = models.NewEntry(e.Uid, e.Project, e.Note.String, e.recordedDatetime())
+	if strings.EqualFold(e.Project, constants.HELLO) {
+		return entry, nil
+	}
 
-		records = append(records, entry)
+	properties, err := db.GetProperties(entry.Uid)
+	if err != nil {
+		return models.Entry{}, err
 	}
 
-	var entries = []models.Entry{}
-	for _, e := range records {
-		var entry models.Entry = models.NewEntry(e.Uid, e.Project, e.Note.String, e.EntryDatetime)
-		var properties []Property = db.GetProperties(entry.Uid)
-		for _, p := range properties {
-			entry.AddEntryProperty(p.Name.String, p.Value.String)
-		}
-		entries = append(entries, entry)
+	for _, p := range properties {
+		entry.AddEntryProperty(p.Name.String, p.Value.String)
 	}
 
-	return entries
+	return entry, nil
 }
 
-func (db *Database) getEntry(uid int64) models.Entry {
-	var s string = fmt.Sprintf("SELECT e.uid, e.project, e.note, e.entry_datetime FROM entry e WHERE e.uid = %d ORDER BY entry_datetime;", uid)
-	results, err := db.Conn.QueryContext(db.Context, s)
+func (db *Database) GetFirstEntry() (models.Entry, error) {
+	var firstUid int64
+	err := db.Conn.QueryRowContext(db.Context, "SELECT e.uid FROM entry e ORDER BY entry_datetime LIMIT 1;").Scan(&firstUid)
 	if err != nil {
-		log.Fatalf("%s: Error trying to retrieve Uid's Entry records. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return models.Entry{}, wrapError("Error trying to retrieve first Uid", err)
 	}
 
-	records := []Entry{}
-	for results.Next() {
-		var entry Entry
-		err = results.Scan(&entry.Uid, &entry.Project, &entry.Note, &entry.EntryDatetime)
-		if err != nil {
-			log.Fatalf("%s: Error trying to Scan Uid's Entries results into data structure. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
-		}
+	// Create entry from the data from the database.
+	return db.GetEntry(firstUid)
+}
 
-		records = append(records, entry)
+func (db *Database) GetLastEntry() (models.Entry, error) {
+	var lastUid int64
+	err := db.Conn.QueryRowContext(db.Context, "SELECT e.uid FROM entry e ORDER BY entry_datetime DESC LIMIT 1;").Scan(&lastUid)
+	if err != nil {
+		return models.Entry{}, wrapError("Error trying to retrieve last Uid", err)
 	}
 
-	results.Close()
+	// Create entry from the data from the database.
+	return db.GetEntry(lastUid)
+}
 
-	var entry models.Entry
-	for i, e := range records {
-		if i == 0 {
-			entry = models.NewEntry(e.Uid, e.Project, e.Note.String, e.EntryDatetime)
-			if strings.EqualFold(e.Project, constants.HELLO) {
-				break
-			}
-		}
+func (db *Database) GetCountEntries() (int64, error) {
+	var count int64
+	err := db.Conn.QueryRowContext(db.Context, "SELECT COUNT(*) FROM entry;").Scan(&count)
+	if err != nil {
+		return 0, wrapError("Error trying to retrieve count of entries", err)
+	}
 
-		var properties []Property = db.GetProperties(entry.Uid)
-		for _, p := range properties {
-			entry.AddEntryProperty(p.Name.String, p.Value.String)
-		}
+	return count, nil
+}
+
+func (db *Database) GetStoreUid() (string, error) {
+	var uid string
+	err := db.Conn.QueryRowContext(db.Context, "SELECT uid FROM store;").Scan(&uid)
+	if err != nil {
+		return constants.EMPTY, wrapError("Error trying to retrieve the store's uid", err)
 	}
 
-	return entry
+	return uid, nil
+}
+
+// Get the Entries, with their properties, selected by the filter ordered by
+// date/time.
+func (db *Database) GetEntriesMatching(filter EntryFilter) ([]models.Entry, error) {
+	where, args := filter.where()
+	return db.queryEntries(where, args...)
 }
 
-func (db *Database) GetFirstEntry() models.Entry {
-	result, err := db.Conn.QueryContext(db.Context, "SELECT e.uid FROM entry e ORDER BY entry_datetime LIMIT 1;")
+// Get the Entries, with their properties, whose project, tasks, note, or url
+// match the full-text search query, e.g. '"database migration"' or 'migrat*',
+// and that are selected by the filter, ordered by date/time.
+func (db *Database) SearchEntries(query string, filter EntryFilter) ([]models.Entry, error) {
+	where, args := filter.where()
+	return db.queryEntries("e.uid IN (SELECT rowid FROM entry_fts WHERE entry_fts MATCH ?) AND "+where, append([]any{query}, args...)...)
+}
+
+// Move the Entries, and their properties, selected by the filter to the trash.
+func (db *Database) NukeEntries(filter EntryFilter) (int64, error) {
+	// Create a transaction.
+	tx, err := db.Conn.BeginTx(db.Context, nil)
 	if err != nil {
-		log.Fatalf("%s: Error trying to retrieve first Uid. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return 0, wrapError("Error trying to begin transaction", err)
+	}
+
+	// Via the transaction, move all the matching entry and associated
+	// property records to the trash.
+	var count int64
+	journalUid, err := db.startOperation(tx, constants.NUKE, constants.ACTION_DELETE)
+	if err == nil {
+		where, args := filter.where()
+		count, err = db.moveToTrash(tx, journalUid, where, args...)
 	}
 
-	var firstUid int64
-	result.Next()
-	err = result.Scan(&firstUid)
 	if err != nil {
-		log.Fatalf("%s: Error trying to Scan first Uid into data structure. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		tx.Rollback()
+		return 0, wrapError("Error trying to nuke entry records", err)
 	}
 
-	result.Close()
+	err = tx.Commit()
+	if err != nil {
+		return 0, wrapError("Error committing transaction", err)
+	}
 
-	// Create entry from the data from the database.
-	var entry models.Entry = db.getEntry(firstUid)
+	return count, nil
+}
 
-	return entry
+// Trash the Entries with the specified uids, as deleted by 'tt delete'.
+func (db *Database) DeleteEntries(uids []int64) (int64, error) {
+	return db.TrashEntries(uids, constants.DELETE)
 }
 
-func (db *Database) GetLastEntry() models.Entry {
-	result, err := db.Conn.QueryContext(db.Context, "SELECT e.uid FROM entry e ORDER BY entry_datetime DESC LIMIT 1;")
+// Move the Entries with the specified uids, along with their properties, to
+// the trash, journaling it as performed by the specified command.
+func (db *Database) TrashEntries(uids []int64, command string) (int64, error) {
+	tx, err := db.Conn.BeginTx(db.Context, nil)
 	if err != nil {
-		log.Fatalf("%s: Error trying to retrieve last Uid. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return 0, wrapError("Error trying to begin transaction", err)
+	}
+
+	var count int64
+	journalUid, err := db.startOperation(tx, command, constants.ACTION_DELETE)
+	if err == nil {
+		count, err = db.trashUids(tx, journalUid, uids)
 	}
 
-	var lastUid int64
-	result.Next()
-	err = result.Scan(&lastUid)
 	if err != nil {
-		log.Fatalf("%s: Error trying to Scan last Uid into data structure. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		tx.Rollback()
+		return 0, wrapError("Error trying to delete entries", err)
 	}
 
-	result.Close()
+	err = tx.Commit()
+	if err != nil {
+		return 0, wrapError("Error committing transaction", err)
+	}
 
-	// Create entry from the data from the database.
-	var entry models.Entry = db.getEntry(lastUid)
+	return count, nil
+}
 
-	return entry
+// Update the non-empty fields of the Entry with the matching uid, recording
+// each field that actually changes, and the command that changed it, in the
+// entry_history table.
+func (db *Database) UpdateEntry(entry models.Entry, command string) error {
+	return retryIfBusy(func() error {
+		return db.updateEntry(entry, command)
+	})
 }
 
-func (db *Database) GetCountEntries() int64 {
-	result, err := db.Conn.QueryContext(db.Context, "SELECT COUNT(*) FROM entry;")
+func (db *Database) updateEntry(entry models.Entry, command string) error {
+	tx, err := db.Conn.BeginTx(db.Context, nil)
 	if err != nil {
-		log.Fatalf("%s: Error trying to retrieve count of entries. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		return wrapError("Error trying to begin transaction", err)
 	}
 
-	var count int64
-	result.Next()
-	err = result.Scan(&count)
+	var old Entry
+	err = tx.QueryRowContext(db.Context, "SELECT e.project, e.note, e.entry_datetime, e.entry_offset FROM entry e WHERE e.uid = ?;", entry.Uid).Scan(&old.Project, &old.Note, &old.EntryDatetime, &old.EntryOffset)
 	if err != nil {
-		log.Fatalf("%s: Error trying to Scan count. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+		tx.Rollback()
+		return wrapError("Error trying to retrieve Uid's Entry record", err)
 	}
 
-	result.Close()
+	var changedDatetime string = carbon.Now().SetTimezone(carbon.UTC).ToRfc3339String()
+	var changes []models.Change
+	var columns []string
+	var args []any
 
-	return count
-}
+	// Update only the Entry columns that were specified and are different.
+	if entry.Project != constants.EMPTY && entry.Project != old.Project {
+		columns = append(columns, "project = ?")
+		args = append(args, entry.Project)
+		changes = append(changes, models.NewChange(entry.Uid, constants.PROJECT, old.Project, entry.Project, changedDatetime, command))
+	}
+
+	if len(entry.Note) > 0 && entry.Note != old.Note.String {
+		columns = append(columns, "note = ?")
+		args = append(args, entry.Note)
+		changes = append(changes, models.NewChange(entry.Uid, constants.NOTE, old.Note.String, entry.Note, changedDatetime, command))
+	}
 
-func (db *Database) NukePriorYearsEntries(dryRun bool, year int) int64 {
-	var count int64 = 0
-	var query strings.Builder
+	if entry.EntryDatetime != constants.EMPTY && entry.EntryDatetime != old.recordedDatetime() {
+		entryDatetime, entryOffset := toUtc(entry.EntryDatetime)
+		columns = append(columns, "entry_datetime = ?", "entry_offset = ?")
+		args = append(args, entryDatetime, entryOffset)
+		changes = append(changes, models.NewChange(entry.Uid, constants.ENTRY_DATETIME, old.recordedDatetime(), entry.EntryDatetime, changedDatetime, command))
+	}
 
-	if !dryRun {
-		query.WriteString(fmt.Sprintf("%s != '%d';", "DELETE FROM entry WHERE strftime('%Y', entry_datetime)", year))
+	if len(columns) > 0 {
+		var query string = "UPDATE entry SET " + strings.Join(columns, ", ") + " WHERE uid = ?;"
+		args = append(args, entry.Uid)
 
-		// Create a transaction.
-		tx, err := db.Conn.BeginTx(db.Context, nil)
-		if err != nil {
-			log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+		if viper.GetBool("debug") {
+			log.Printf("Query[%s] Args%v\n", query, args)
 		}
 
-		// Via the transaction, delete all the entry and associated property records.
-		_, err = tx.ExecContext(db.Context, query.String())
+		// Execute the update.
+		_, err := tx.ExecContext(db.Context, query, args...)
 		if err != nil {
-			log.Fatalf("%s: Error trying to delete all entry before %d. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), year, err.Error())
 			tx.Rollback()
-			os.Exit(1)
-		} else {
-			err = tx.Commit()
-			if err != nil {
-				log.Fatalf("%s: Error committing transaction. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-				os.Exit(1)
-			}
+			return wrapError("Error trying to update entry", err)
 		}
-	} else {
-		query.WriteString(fmt.Sprintf("%s != '%d';", "SELECT COUNT(*) FROM entry WHERE strftime('%Y', entry_datetime)", year))
-		result, err := db.Conn.QueryContext(db.Context, query.String())
+	}
+
+	// Update the TASK property if one exists.
+	var task = entry.GetTasksAsString()
+	if len(task) > 0 {
+		oldValue, changed, err := db.updateProperty(tx, entry.Uid, constants.TASK, task)
 		if err != nil {
-			log.Fatalf("%s: Error trying to retrieve count of entries. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+			tx.Rollback()
+			return wrapError("Error trying to update task property", err)
 		}
 
-		result.Next()
-		err = result.Scan(&count)
+		if changed {
+			changes = append(changes, models.NewChange(entry.Uid, constants.TASK, oldValue, task, changedDatetime, command))
+		}
+	}
+
+	// Update the URL property if one exists.
+	var url = entry.GetUrlAsString()
+	if len(url) > 0 {
+		oldValue, changed, err := db.updateProperty(tx, entry.Uid, constants.URL, url)
 		if err != nil {
-			log.Fatalf("%s: Error trying to Scan count. %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+			tx.Rollback()
+			return wrapError("Error trying to update url property", err)
 		}
 
-		result.Close()
+		if changed {
+			changes = append(changes, models.NewChange(entry.Uid, constants.URL, oldValue, url, changedDatetime, command))
+		}
 	}
 
-	return count
-}
-
-func (db *Database) NukeAllEntries(dryRun bool) int64 {
-	var count int64 = 0
+	err = db.recordHistory(tx, changes)
+	if err != nil {
+		tx.Rollback()
+		return wrapError("Error trying to insert entry history", err)
+	}
 
-	if !dryRun {
-		// Create a transaction.
-		tx, err := db.Conn.BeginTx(db.Context, nil)
-		if err != nil {
-			log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+	// Only journal updates that actually changed something.
+	if len(changes) > 0 {
+		journalUid, err := db.startOperation(tx, command, constants.ACTION_UPDATE)
+		for i := 0; err == nil && i < len(changes); i++ {
+			err = db.journalChange(tx, journalUid, changes[i])
 		}
 
-		// Via the transaction, delete all the entry and associated property records.
-		_, err = tx.ExecContext(db.Context, "DELETE FROM entry;")
 		if err != nil {
-			log.Fatalf("%s: Error trying to delete all entry records. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
 			tx.Rollback()
-			os.Exit(1)
-		} else {
-			err = tx.Commit()
-			if err != nil {
-				log.Fatalf("%s: Error committing transaction. %s.\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-				os.Exit(1)
-			}
+			return wrapError("Error trying to journal entry changes", err)
 		}
-	} else {
-		count = db.GetCountEntries()
-		log.Printf("%d entries would have been nuked.", count)
 	}
 
-	return count
-}
-
-func (db *Database) UpdateEntry(entry models.Entry) {
-	var previous bool = false
-	var query strings.Builder
+	err = tx.Commit()
+	if err != nil {
+		return wrapError("Error committing transaction", err)
+	}
 
-	// Update the Entry.
-	query.WriteString("UPDATE entry")
-	query.WriteString(" SET")
+	return nil
+}
 
-	if entry.Project != constants.EMPTY {
-		query.WriteString(fmt.Sprintf(" project = '%s'", entry.Project))
-		previous = true
+// Update the existing property records with the specified name.  Returns the
+// old value and whether it was actually changed.
+func (db *Database) updateProperty(tx *sql.Tx, entryUid int64, name string, value string) (string, bool, error) {
+	// Multiple properties with the same name are shown joined together, so
+	// join them the same way to get the old value.
+	var oldValue sql.NullString
+	err := tx.QueryRowContext(db.Context, "SELECT group_concat(value, ', ') FROM (SELECT value FROM property WHERE entry_uid = ? AND name = ? ORDER BY rowid);", entryUid, name).Scan(&oldValue)
+	if err != nil {
+		return constants.EMPTY, false, err
 	}
 
-	if len(entry.Note) > 0 {
-		if previous {
-			query.WriteString(", ")
-		}
-		query.WriteString(fmt.Sprintf(" note = '%s'", entry.Note))
-		previous = true
+	// Like before, only existing properties are updated.
+	if !oldValue.Valid || oldValue.String == value {
+		return oldValue.String, false, nil
 	}
 
-	if entry.EntryDatetime != constants.EMPTY {
-		if previous {
-			query.WriteString(", ")
-		}
-		query.WriteString(fmt.Sprintf(" entry_datetime = '%s'", entry.EntryDatetime))
+	_, err = db.replaceProperty(tx, entryUid, name, value)
+	if err != nil {
+		return constants.EMPTY, false, err
 	}
 
-	query.WriteString(fmt.Sprintf(" WHERE uid = %d;", entry.Uid))
+	return oldValue.String, true, nil
+}
 
+// Replace the property records with the specified name by one record for each
+// of the values joined together in value, e.g. two tasks shown as "design,
+// review".  Updating the records in place would give each of them the joined
+// value.  Returns the number of records replaced, and adds none if there were
+// none to replace.
+func (db *Database) replaceProperty(tx *sql.Tx, entryUid int64, name string, value string) (int64, error) {
 	if viper.GetBool("debug") {
-		log.Printf("Query[%s]\n", query.String())
+		log.Printf("Replace property[%s] of Uid[%d] with[%s]\n", name, entryUid, value)
 	}
 
-	// Execute the update.
-	_, err := db.Conn.ExecContext(db.Context, query.String())
-	if err != nil {
-		log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-		os.Exit(1)
+	count, err := rowsAffected(tx.ExecContext(db.Context, "DELETE FROM property WHERE entry_uid = ? AND name = ?;", entryUid, name))
+	if err != nil || count == 0 {
+		return count, err
 	}
 
-	// Update the TASK property if one exists.
-	var task = entry.GetTasksAsString()
-	if len(task) > 0 {
-		query.Reset()
-		query.WriteString("UPDATE property")
-		query.WriteString(" SET")
-		query.WriteString(fmt.Sprintf(" value = '%s'", task))
-		query.WriteString(fmt.Sprintf(" WHERE entry_uid = %d and name = '%s';", entry.Uid, constants.TASK))
-
-		if viper.GetBool("debug") {
-			log.Printf("Query[%s]\n", query.String())
-		}
-
-		// Execute the update.
-		_, err = db.Conn.ExecContext(db.Context, query.String())
+	for _, v := range splitPropertyValue(value) {
+		_, err = tx.ExecContext(db.Context, "INSERT INTO property (entry_uid, name, value) VALUES (?, ?, ?);", entryUid, name, v)
 		if err != nil {
-			log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+			return 0, err
 		}
 	}
 
-	// Update the URL property if one exists.
-	var url = entry.GetUrlAsString()
-	if len(url) > 0 {
-		query.Reset()
-		query.WriteString("UPDATE property")
-		query.WriteString(" SET")
-		query.WriteString(fmt.Sprintf(" value = '%s'", url))
-		query.WriteString(fmt.Sprintf(" WHERE entry_uid = %d and name = '%s';", entry.Uid, constants.URL))
+	return count, nil
+}
 
-		if viper.GetBool("debug") {
-			log.Printf("Query[%s]\n", query.String())
-		}
+func (db *Database) GetHistory(entryUid int64) ([]models.Change, error) {
+	return db.queryHistory("SELECT uid, entry_uid, field, old_value, new_value, changed_datetime, command FROM entry_history WHERE entry_uid = ? ORDER BY uid;", entryUid)
+}
 
-		// Execute the update.
-		_, err = db.Conn.ExecContext(db.Context, query.String())
+func (db *Database) GetHistorySince(since carbon.Carbon) ([]models.Change, error) {
+	return db.queryHistory("SELECT uid, entry_uid, field, old_value, new_value, changed_datetime, command FROM entry_history WHERE changed_datetime >= ? ORDER BY uid;", since.SetTimezone(carbon.UTC).ToRfc3339String())
+}
+
+func (db *Database) queryHistory(query string, args ...any) ([]models.Change, error) {
+	results, err := db.Conn.QueryContext(db.Context, query, args...)
+	if err != nil {
+		return nil, wrapError("Error trying to retrieve Entry history records", err)
+	}
+
+	defer results.Close()
+
+	changes := []models.Change{}
+	for results.Next() {
+		var c models.Change
+		var oldValue, newValue sql.NullString
+		err = results.Scan(&c.Uid, &c.EntryUid, &c.Field, &oldValue, &newValue, &c.ChangedDatetime, &c.Command)
 		if err != nil {
-			log.Fatalf("%s: %s\n", color.RedString(constants.FATAL_NORMAL_CASE), err.Error())
-			os.Exit(1)
+			return nil, wrapError("Error trying to Scan Entry history results into data structure", err)
 		}
+
+		c.OldValue = oldValue.String
+		c.NewValue = newValue.String
+		changes = append(changes, c)
 	}
+
+	return changes, wrapError("Error trying to retrieve Entry history records", results.Err())
 }
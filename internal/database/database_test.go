@@ -0,0 +1,516 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+	"github.com/spf13/viper"
+)
+
+// Text that breaks SQL built by string formatting: quotes, semicolons,
+// comments, LIKE wildcards, backslashes, and characters outside of ASCII.
+var awkwardText = []string{
+	"customer's bug",
+	`say "hello"`,
+	"a; DROP TABLE entry; --",
+	"100% of _everything_",
+	`C:\temp\new`,
+	"Ünïcödé ñandú",
+	"日本語のメモ",
+	"🚀 launch 🎉",
+	"tab\tand\nnewline",
+}
+
+// Open a new, empty database in a temporary file.
+func newTestDatabase(t testing.TB) *Database {
+	t.Helper()
+
+	db, err := New(filepath.Join(t.TempDir(), "timetracker.db"))
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// Run the test against both the SQLite Database and the MemoryStore, so the
+// two stay interchangeable.
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	t.Run("Database", func(t *testing.T) { test(t, newTestDatabase(t)) })
+	t.Run("MemoryStore", func(t *testing.T) { test(t, NewMemoryStore()) })
+}
+
+// Get the single Entry with the uid, failing the test if it is missing.
+func mustGetEntry(t *testing.T, store Store, uid int64) models.Entry {
+	t.Helper()
+
+	e, err := store.GetEntry(uid)
+	if err != nil {
+		t.Fatalf("GetEntry(%d) failed: %v", uid, err)
+	}
+
+	return e
+}
+
+func TestAwkwardTextRoundTrips(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		for i, text := range awkwardText {
+			var added string = carbon.CreateFromDateTime(2024, 3, 5, 9, i, 0, "Europe/Paris").ToRfc3339String()
+
+			// Add, the same way 'tt add' does.
+			var entry models.Entry = models.NewEntry(constants.UNKNOWN_UID, text, text, added)
+			entry.AddEntryProperty(constants.TASK, text)
+			entry.AddEntryProperty(constants.URL, "https://example.com/?q="+text)
+			if err := store.InsertNewEntry(entry, "add"); err != nil {
+				t.Fatalf("InsertNewEntry(%q) failed: %v", text, err)
+			}
+
+			last, err := store.GetLastEntry()
+			if err != nil {
+				t.Fatalf("GetLastEntry() failed: %v", err)
+			}
+
+			if last.Project != text || last.Note != text || last.GetTasksAsString() != text || last.EntryDatetime != added {
+				t.Fatalf("added %q, got Project[%q] Note[%q] Tasks[%q] Date[%s]", text, last.Project, last.Note, last.GetTasksAsString(), last.EntryDatetime)
+			}
+
+			// Amend the project, task, and note, the same way 'tt amend' does.
+			var amended string = "amended " + text
+			var amend models.Entry = models.NewEntry(last.Uid, amended, amended, constants.EMPTY)
+			amend.AddEntryProperty(constants.TASK, amended)
+			if err := store.UpdateEntry(amend, constants.AMEND); err != nil {
+				t.Fatalf("UpdateEntry(%q) failed: %v", amended, err)
+			}
+
+			var got models.Entry = mustGetEntry(t, store, last.Uid)
+			if got.Project != amended || got.Note != amended || got.GetTasksAsString() != amended {
+				t.Fatalf("amended to %q, got Project[%q] Note[%q] Tasks[%q]", amended, got.Project, got.Note, got.GetTasksAsString())
+			}
+
+			// Stretch it, the same way 'tt stretch' does.
+			var stretched string = carbon.Parse(added).AddSeconds(30).ToRfc3339String()
+			if err := store.UpdateEntry(models.Entry{Uid: last.Uid, EntryDatetime: stretched}, constants.STRETCH); err != nil {
+				t.Fatalf("UpdateEntry() to stretch failed: %v", err)
+			}
+
+			got = mustGetEntry(t, store, last.Uid)
+			if got.EntryDatetime != stretched || got.Project != amended || got.Note != amended {
+				t.Fatalf("stretched to %s, got Project[%q] Note[%q] Date[%s]", stretched, got.Project, got.Note, got.EntryDatetime)
+			}
+
+			// The history keeps the old and new values intact.
+			history, err := store.GetHistory(last.Uid)
+			if err != nil {
+				t.Fatalf("GetHistory(%d) failed: %v", last.Uid, err)
+			}
+
+			var found bool = false
+			for _, change := range history {
+				if change.Field == constants.NOTE && change.OldValue == text && change.NewValue == amended {
+					found = true
+				}
+			}
+
+			if !found {
+				t.Fatalf("history of %q is missing the note change: %+v", text, history)
+			}
+		}
+
+		// Report, the same way 'tt report' loads the entries.
+		entries, err := store.GetEntriesBetween(carbon.CreateFromDate(2024, 3, 5, "Europe/Paris").StartOfDay(), carbon.CreateFromDate(2024, 3, 5, "Europe/Paris").EndOfDay())
+		if err != nil {
+			t.Fatalf("GetEntriesBetween() failed: %v", err)
+		}
+
+		if len(entries) != len(awkwardText) {
+			t.Fatalf("reported %d entries, want %d", len(entries), len(awkwardText))
+		}
+
+		for i, e := range entries {
+			var want string = "amended " + awkwardText[i]
+			if e.Project != want || e.Note != want || e.GetTasksAsString() != want || e.GetUrlAsString() != "https://example.com/?q="+awkwardText[i] {
+				t.Errorf("reported entry %d as Project[%q] Note[%q] Tasks[%q] Url[%q], want %q", i, e.Project, e.Note, e.GetTasksAsString(), e.GetUrlAsString(), want)
+			}
+		}
+
+		// The filters bind the text rather than splicing it into the query.
+		for _, text := range awkwardText {
+			var want string = "amended " + text
+			matching, err := store.GetEntriesMatching(EntryFilter{Project: want, Task: want})
+			if err != nil {
+				t.Fatalf("GetEntriesMatching(%q) failed: %v", want, err)
+			}
+
+			if len(matching) != 1 || matching[0].Project != want {
+				t.Errorf("GetEntriesMatching(%q) = %d entries, want 1", want, len(matching))
+			}
+		}
+	})
+}
+
+// Seed the database with count entries, one every 15 minutes going back from
+// the start of 2024, each with a task and every tenth one with a url.
+func seedEntries(b *testing.B, db *Database, count int) {
+	b.Helper()
+
+	var entries []models.Entry = make([]models.Entry, 0, count)
+	var start carbon.Carbon = carbon.CreateFromDate(2024, 1, 1, carbon.UTC).StartOfDay()
+	for i := 0; i < count; i++ {
+		var e models.Entry = models.NewEntry(constants.UNKNOWN_UID, fmt.Sprintf("project %d", i%20), fmt.Sprintf("note %d", i), start.SubMinutes(15*(count-i)).ToRfc3339String())
+		e.AddEntryProperty(constants.TASK, fmt.Sprintf("task %d", i%50))
+		if i%10 == 0 {
+			e.AddEntryProperty(constants.URL, fmt.Sprintf("https://example.com/%d", i))
+		}
+
+		entries = append(entries, e)
+	}
+
+	if _, err := db.InsertNewEntries(entries, "seed"); err != nil {
+		b.Fatalf("InsertNewEntries() failed: %v", err)
+	}
+}
+
+// Load a year of entries out of 100k, about 35k of them, the way a yearly
+// report does.
+func BenchmarkGetEntriesBetween(b *testing.B) {
+	var db *Database = newTestDatabase(b)
+	seedEntries(b, db, 100_000)
+
+	var start carbon.Carbon = carbon.CreateFromDate(2023, 1, 1, carbon.UTC).StartOfDay()
+	var end carbon.Carbon = carbon.CreateFromDate(2023, 12, 31, carbon.UTC).EndOfDay()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		entries, err := db.GetEntriesBetween(start, end)
+		if err != nil {
+			b.Fatalf("GetEntriesBetween() failed: %v", err)
+		}
+
+		if len(entries) != 365*24*4 {
+			b.Fatalf("GetEntriesBetween() = %d entries, want %d", len(entries), 365*24*4)
+		}
+	}
+}
+
+// Run writers and readers in parallel against one file, each with its own
+// connection the way separate tt processes would have, and make sure none of
+// them sees the database locked and no entry goes missing.
+func TestConcurrentWritersAndReaders(t *testing.T) {
+	viper.Set(constants.BUSY_TIMEOUT, 5000)
+	defer viper.Set(constants.BUSY_TIMEOUT, nil)
+
+	const writers int = 4
+	const readers int = 4
+	const entriesPerWriter int = 50
+
+	var filename string = newTestDatabase(t).Filename
+	var open = func() *Database {
+		db, err := Open(filename)
+		if err != nil {
+			t.Fatalf("Open() failed: %v", err)
+		}
+
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+
+	var errs chan error = make(chan error, writers*entriesPerWriter+readers)
+	var done chan struct{} = make(chan struct{})
+	var day carbon.Carbon = carbon.CreateFromDate(2024, 3, 5, carbon.UTC)
+
+	var writing sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		var db *Database = open()
+		writing.Add(1)
+		go func(w int) {
+			defer writing.Done()
+			for i := 0; i < entriesPerWriter; i++ {
+				var e models.Entry = models.NewEntry(constants.UNKNOWN_UID, fmt.Sprintf("writer %d", w), fmt.Sprintf("entry %d", i), day.AddSeconds(w*entriesPerWriter+i).ToRfc3339String())
+				e.AddEntryProperty(constants.TASK, "stress")
+				if err := db.InsertNewEntry(e, "add"); err != nil {
+					errs <- fmt.Errorf("writer %d, entry %d: %w", w, i, err)
+				}
+			}
+		}(w)
+	}
+
+	var reading sync.WaitGroup
+	for r := 0; r < readers; r++ {
+		var db *Database = open()
+		reading.Add(1)
+		go func(r int) {
+			defer reading.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				if _, err := db.GetEntriesBetween(day.StartOfDay(), day.EndOfDay()); err != nil {
+					errs <- fmt.Errorf("reader %d: %w", r, err)
+					return
+				}
+			}
+		}(r)
+	}
+
+	writing.Wait()
+	close(done)
+	reading.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("%v", err)
+	}
+
+	entries, err := open().GetEntriesBetween(day.StartOfDay(), day.EndOfDay())
+	if err != nil {
+		t.Fatalf("GetEntriesBetween() failed: %v", err)
+	}
+
+	if len(entries) != writers*entriesPerWriter {
+		t.Fatalf("found %d entries, want %d", len(entries), writers*entriesPerWriter)
+	}
+
+	// Every entry, in the order it was made, has its own uid and its task.
+	var uids map[int64]bool = make(map[int64]bool)
+	for i, e := range entries {
+		var want string = fmt.Sprintf("writer %d/entry %d", i/entriesPerWriter, i%entriesPerWriter)
+		if e.Project+"/"+e.Note != want || e.GetTasksAsString() != "stress" || uids[e.Uid] {
+			t.Errorf("entry %d is Uid[%d] Project[%s] Note[%s] Tasks[%s], want %s", i, e.Uid, e.Project, e.Note, e.GetTasksAsString(), want)
+		}
+
+		uids[e.Uid] = true
+	}
+}
+
+// An entry moved to an archive keeps its uid there, so new entries must not
+// be given it, otherwise reports including the archives would mix them up.
+func TestArchivedUidsAreNotReused(t *testing.T) {
+	var db *Database = newTestDatabase(t)
+	for _, entryDatetime := range []string{"2024-06-05T10:00:00Z", "2023-06-05T09:00:00Z", "2023-06-05T10:00:00Z"} {
+		if err := db.InsertNewEntry(models.NewEntry(constants.UNKNOWN_UID, "acme", constants.EMPTY, entryDatetime), "add"); err != nil {
+			t.Fatalf("InsertNewEntry() failed: %v", err)
+		}
+	}
+
+	var filename string = filepath.Join(t.TempDir(), "archive-2023.db")
+	if count, err := db.ArchiveYear("2023", carbon.CreateFromDate(2024, 1, 1, carbon.UTC), filename); err != nil || count != 2 {
+		t.Fatalf("ArchiveYear() = %d, %v, want 2 entries archived", count, err)
+	}
+
+	if err := db.InsertNewEntry(models.NewEntry(constants.UNKNOWN_UID, "acme", constants.EMPTY, "2024-06-05T11:00:00Z"), "add"); err != nil {
+		t.Fatalf("InsertNewEntry() failed: %v", err)
+	}
+
+	last, err := db.GetLastEntry()
+	if err != nil {
+		t.Fatalf("GetLastEntry() failed: %v", err)
+	}
+
+	if last.Uid != 4 {
+		t.Fatalf("the entry added after archiving uids 2 and 3 got uid %d, want 4", last.Uid)
+	}
+}
+
+// The values of the Entry's properties with the name, in order.
+func propertyValues(e models.Entry, name string) []string {
+	var values []string
+	for _, p := range e.Properties {
+		if p.Name == name {
+			values = append(values, p.Value)
+		}
+	}
+
+	return values
+}
+
+func TestAmendEntryWithTwoTasks(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		var entry models.Entry = models.NewEntry(constants.UNKNOWN_UID, "acme", constants.EMPTY, "2024-03-05T09:00:00Z")
+		entry.AddEntryProperty(constants.TASK, "design")
+		entry.AddEntryProperty(constants.TASK, "review")
+		if err := store.InsertNewEntry(entry, "add"); err != nil {
+			t.Fatalf("InsertNewEntry() failed: %v", err)
+		}
+
+		last, err := store.GetLastEntry()
+		if err != nil {
+			t.Fatalf("GetLastEntry() failed: %v", err)
+		}
+
+		// Amend the tasks the way 'tt amend' does, with the tasks joined
+		// together the way they are shown.
+		var amend = func(tasks string) {
+			t.Helper()
+
+			var e models.Entry
+			e.Uid = last.Uid
+			e.AddEntryProperty(constants.TASK, tasks)
+			if err := store.UpdateEntry(e, constants.AMEND); err != nil {
+				t.Fatalf("UpdateEntry(%q) failed: %v", tasks, err)
+			}
+		}
+
+		var expect = func(when string, tasks ...string) {
+			t.Helper()
+
+			var got []string = propertyValues(mustGetEntry(t, store, last.Uid), constants.TASK)
+			if fmt.Sprint(got) != fmt.Sprint(tasks) {
+				t.Fatalf("%s, the tasks are %q, want %q", when, got, tasks)
+			}
+		}
+
+		// Leaving the tasks as they are changes nothing.
+		amend("design, review")
+		expect("after amending nothing", "design", "review")
+
+		amend("design, testing")
+		expect("after amending", "design", "testing")
+
+		history, err := store.GetHistory(last.Uid)
+		if err != nil {
+			t.Fatalf("GetHistory() failed: %v", err)
+		}
+
+		if len(history) != 1 || history[0].OldValue != "design, review" || history[0].NewValue != "design, testing" {
+			t.Fatalf("the history is %+v, want one change from \"design, review\" to \"design, testing\"", history)
+		}
+
+		if _, err := store.Undo(); err != nil {
+			t.Fatalf("Undo() failed: %v", err)
+		}
+
+		expect("after undo", "design", "review")
+
+		if _, err := store.Redo(); err != nil {
+			t.Fatalf("Redo() failed: %v", err)
+		}
+
+		expect("after redo", "design", "testing")
+	})
+}
+
+func TestMigrationStoresDatetimesInUtc(t *testing.T) {
+	var tests = []struct {
+		name          string
+		recorded      string
+		entryDatetime string
+		entryOffset   sql.NullString
+	}{
+		{"negative offset", "2024-03-10T23:30:00-04:00", "2024-03-11T03:30:00Z", sql.NullString{String: "-04:00", Valid: true}},
+		{"positive offset", "2024-01-05T08:00:00+01:00", "2024-01-05T07:00:00Z", sql.NullString{String: "+01:00", Valid: true}},
+		{"utc", "2024-01-05T08:00:00Z", "2024-01-05T08:00:00Z", sql.NullString{String: "+00:00", Valid: true}},
+		{"no offset", "2024-01-05 08:00:00", "2024-01-05 08:00:00", sql.NullString{}},
+		{"unparsable", "sometime", "sometime", sql.NullString{}},
+	}
+
+	// Create the schema as it was before migration 6 and fill it the way
+	// earlier versions did, with local date/times.
+	db, err := Open(filepath.Join(t.TempDir(), "timetracker.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+
+	defer db.Close()
+
+	if _, err := db.Conn.Exec("CREATE TABLE schema_version (version INTEGER NOT NULL PRIMARY KEY, description TEXT NOT NULL, applied_datetime TEXT NOT NULL);"); err != nil {
+		t.Fatalf("creating schema_version failed: %v", err)
+	}
+
+	for _, m := range migrations[:5] {
+		if err := db.applyMigration(m); err != nil {
+			t.Fatalf("applyMigration(%d) failed: %v", m.Version, err)
+		}
+	}
+
+	for i, test := range tests {
+		if _, err := db.Conn.Exec("INSERT INTO entry (uid, project, entry_datetime) VALUES (?, ?, ?);", i+1, test.name, test.recorded); err != nil {
+			t.Fatalf("inserting entry %q failed: %v", test.name, err)
+		}
+
+		if _, err := db.Conn.Exec("INSERT INTO trash_entry (uid, project, entry_datetime, deleted_datetime) VALUES (?, ?, ?, ?);", i+101, test.name, test.recorded, test.recorded); err != nil {
+			t.Fatalf("inserting trash_entry %q failed: %v", test.name, err)
+		}
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		t.Fatalf("Migrate() failed: %v", err)
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, table := range []string{"entry", "trash_entry"} {
+				var uid int = i + 1
+				if table == "trash_entry" {
+					uid = i + 101
+				}
+
+				var entryDatetime string
+				var entryOffset sql.NullString
+				if err := db.Conn.QueryRow("SELECT entry_datetime, entry_offset FROM "+table+" WHERE uid = ?;", uid).Scan(&entryDatetime, &entryOffset); err != nil {
+					t.Fatalf("selecting from %s failed: %v", table, err)
+				}
+
+				if entryDatetime != test.entryDatetime || entryOffset != test.entryOffset {
+					t.Errorf("%s has [%s] at offset %+v, want [%s] at offset %+v", table, entryDatetime, entryOffset, test.entryDatetime, test.entryOffset)
+				}
+			}
+
+			// Entries still come back as they were recorded.
+			if e := mustGetEntry(t, db, int64(i+1)); e.EntryDatetime != test.recorded {
+				t.Errorf("GetEntry() returned [%s], want [%s]", e.EntryDatetime, test.recorded)
+			}
+		})
+	}
+}
+
+func TestArchivingForgetsOperationsOnArchivedEntries(t *testing.T) {
+	var db *Database = newTestDatabase(t)
+	for _, entryDatetime := range []string{"2024-06-05T10:00:00Z", "2023-06-05T09:00:00Z"} {
+		if err := db.InsertNewEntry(models.NewEntry(constants.UNKNOWN_UID, "acme", constants.EMPTY, entryDatetime), "add"); err != nil {
+			t.Fatalf("InsertNewEntry() failed: %v", err)
+		}
+	}
+
+	// Amend the entry that is about to be archived.
+	var amended models.Entry
+	amended.Uid = 2
+	amended.Project = "globex"
+	if err := db.UpdateEntry(amended, constants.AMEND); err != nil {
+		t.Fatalf("UpdateEntry() failed: %v", err)
+	}
+
+	var filename string = filepath.Join(t.TempDir(), "archive-2023.db")
+	if count, err := db.ArchiveYear("2023", carbon.CreateFromDate(2024, 1, 1, carbon.UTC), filename); err != nil || count != 1 {
+		t.Fatalf("ArchiveYear() = %d, %v, want 1 entry archived", count, err)
+	}
+
+	// Only adding the entry that is still in the database is left to undo.
+	operations, err := db.GetOperations(false)
+	if err != nil {
+		t.Fatalf("GetOperations() failed: %v", err)
+	}
+
+	if len(operations) != 1 || fmt.Sprint(operations[0].EntryUids) != "[1]" {
+		t.Fatalf("the operations left to undo are %+v, want only the add of entry 1", operations)
+	}
+
+	if _, err := db.Undo(); err != nil {
+		t.Fatalf("Undo() failed: %v", err)
+	}
+
+	if _, err := db.Undo(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Undo() with nothing left to undo returned %v, want ErrNotFound", err)
+	}
+}
@@ -1,7 +0,0 @@
-package database
-
-type DistinctUID struct {
-	Uid           int64
-	Project       string
-	EntryDatetime string
-}
@@ -0,0 +1,46 @@
+package database
+
+// An OrphanedProperty is a property record whose entry no longer exists,
+// left behind from before foreign keys were enforced.
+type OrphanedProperty struct {
+	Rowid    int64
+	EntryUid int64
+	Name     string
+	Value    string
+}
+
+// Get the property records whose entry no longer exists.
+func (db *Database) GetOrphanedProperties() ([]OrphanedProperty, error) {
+	results, err := db.Conn.QueryContext(db.Context, "SELECT p.rowid, p.entry_uid, p.name, p.value FROM property p WHERE p.entry_uid NOT IN (SELECT uid FROM entry) ORDER BY p.rowid;")
+	if err != nil {
+		return nil, wrapError("Error trying to retrieve orphaned Property records", err)
+	}
+
+	defer results.Close()
+
+	properties := []OrphanedProperty{}
+	for results.Next() {
+		var p OrphanedProperty
+		err = results.Scan(&p.Rowid, &p.EntryUid, &p.Name, &p.Value)
+		if err != nil {
+			return nil, wrapError("Error trying to Scan orphaned Property results into data structure", err)
+		}
+
+		properties = append(properties, p)
+	}
+
+	return properties, wrapError("Error trying to retrieve orphaned Property records", results.Err())
+}
+
+// Permanently delete the orphaned property record with the specified rowid.
+// There is no entry to move it to the trash with.
+func (db *Database) DeleteOrphanedProperty(rowid int64) error {
+	_, err := db.Conn.ExecContext(db.Context, "DELETE FROM property WHERE rowid = ? AND entry_uid NOT IN (SELECT uid FROM entry);", rowid)
+	return wrapError("Error trying to delete orphaned property", err)
+}
+
+// Rebuild the database file, reclaiming the space left by deleted records.
+func (db *Database) Vacuum() error {
+	_, err := db.Conn.ExecContext(db.Context, "VACUUM;")
+	return wrapError("Error trying to vacuum database", err)
+}
@@ -7,6 +7,12 @@ type Entry struct {
 	Project       string
 	Note          sql.NullString
 	EntryDatetime string
+	EntryOffset   sql.NullString
 	//Name          sql.NullString // Property Name.
 	//Value         sql.NullString // Property Value.
 }
+
+// The entry's date/time as it was recorded, with its original offset.
+func (e Entry) recordedDatetime() string {
+	return fromUtc(e.EntryDatetime, e.EntryOffset)
+}
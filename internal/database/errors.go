@@ -0,0 +1,88 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// The kinds of errors returned by the database package.  Use errors.Is() to
+// test for them.
+var (
+	ErrNotFound   = errors.New("no entries found")
+	ErrConstraint = errors.New("constraint violation")
+	ErrLocked     = errors.New("database is locked")
+	ErrCorrupt    = errors.New("database is corrupt")
+)
+
+// Error is the error returned by every method of the database package.  It
+// records the operation that failed, the kind of failure and the underlying
+// error.
+type Error struct {
+	Op   string
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Op + ". " + e.Err.Error() + "."
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Kind, e.Err}
+}
+
+// Wrap the specified error, classifying it by kind, as an *Error.
+func wrapError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var e = &Error{Op: op, Err: err}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		e.Kind = ErrNotFound
+		e.Err = ErrNotFound
+		return e
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code {
+		case sqlite3.ErrConstraint:
+			e.Kind = ErrConstraint
+		case sqlite3.ErrBusy, sqlite3.ErrLocked:
+			e.Kind = ErrLocked
+		case sqlite3.ErrCorrupt, sqlite3.ErrNotADB:
+			e.Kind = ErrCorrupt
+		}
+	}
+
+	return e
+}
+
+// How many times to retry a transaction that failed because the database was
+// locked, even after waiting out the busy timeout.
+const busyRetries int = 5
+
+// Run the transaction, retrying it after a short, growing, randomized pause if
+// it fails because another process has the database locked.  The transaction
+// must roll back on failure so it can safely be run again.
+func retryIfBusy(transaction func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = transaction()
+		if !errors.Is(err, ErrLocked) || attempt == busyRetries {
+			return err
+		}
+
+		time.Sleep(time.Duration(50<<attempt+rand.Intn(50)) * time.Millisecond)
+	}
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"strings"
+
+	"timetracker/constants"
+	"timetracker/internal/models"
+)
+
+// EntryFilter selects Entries.  Empty fields do not filter anything, so the
+// zero value selects every Entry.  Date/times are in ISO8601 format and are
+// compared in UTC.
+type EntryFilter struct {
+	// Entries on or after this date/time.
+	From string
+
+	// Entries on or before this date/time.
+	To string
+
+	// Entries strictly before this date/time.
+	Before string
+
+	// Entries for this project, ignoring case.
+	Project string
+
+	// Entries with this task, ignoring case.
+	Task string
+}
+
+// Build the SQL where clause, and its arguments, for the filter.
+func (f EntryFilter) where() (string, []any) {
+	var conditions []string
+	var args []any
+
+	if f.From != constants.EMPTY {
+		conditions = append(conditions, "entry_datetime >= ?")
+		args = append(args, utc(f.From))
+	}
+
+	if f.To != constants.EMPTY {
+		conditions = append(conditions, "entry_datetime <= ?")
+		args = append(args, utc(f.To))
+	}
+
+	if f.Before != constants.EMPTY {
+		conditions = append(conditions, "entry_datetime < ?")
+		args = append(args, utc(f.Before))
+	}
+
+	if f.Project != constants.EMPTY {
+		conditions = append(conditions, "project = ? COLLATE NOCASE")
+		args = append(args, f.Project)
+	}
+
+	if f.Task != constants.EMPTY {
+		conditions = append(conditions, "uid IN (SELECT entry_uid FROM property WHERE name = ? AND value = ? COLLATE NOCASE)")
+		args = append(args, constants.TASK, f.Task)
+	}
+
+	if len(conditions) == 0 {
+		return "1 = 1", args
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// Check if the Entry is selected by the filter.
+func (f EntryFilter) matches(e models.Entry) bool {
+	var entryDatetime string = utc(e.EntryDatetime)
+	if f.From != constants.EMPTY && entryDatetime < utc(f.From) {
+		return false
+	}
+
+	if f.To != constants.EMPTY && entryDatetime > utc(f.To) {
+		return false
+	}
+
+	if f.Before != constants.EMPTY && entryDatetime >= utc(f.Before) {
+		return false
+	}
+
+	if f.Project != constants.EMPTY && !strings.EqualFold(e.Project, f.Project) {
+		return false
+	}
+
+	if f.Task != constants.EMPTY {
+		var found bool = false
+		for _, p := range e.Properties {
+			if p.Name == constants.TASK && strings.EqualFold(p.Value, f.Task) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+// Start journaling a new operation.  Any operations that were undone can no
+// longer be redone once a new operation is performed.  Returns the uid of the
+// journal record.
+func (db *Database) startOperation(tx *sql.Tx, command string, action string) (int64, error) {
+	_, err := tx.ExecContext(db.Context, "DELETE FROM journal WHERE undone = 1;")
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(db.Context, "INSERT INTO journal (command, action, journal_datetime) VALUES (?, ?, ?);", command, action, carbon.Now().SetTimezone(carbon.UTC).ToRfc3339String())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func (db *Database) journalEntry(tx *sql.Tx, journalUid int64, entryUid int64) error {
+	_, err := tx.ExecContext(db.Context, "INSERT INTO journal_entry (journal_uid, entry_uid) VALUES (?, ?);", journalUid, entryUid)
+	return err
+}
+
+func (db *Database) journalChange(tx *sql.Tx, journalUid int64, c models.Change) error {
+	_, err := tx.ExecContext(db.Context, "INSERT INTO journal_entry (journal_uid, entry_uid, field, old_value, new_value) VALUES (?, ?, ?, ?, ?);", journalUid, c.EntryUid, c.Field, c.OldValue, c.NewValue)
+	return err
+}
+
+// Get the operations, with their entries and changes, in the order Store
+// describes using a single query over the journal.
+func (db *Database) GetOperations(undone bool) ([]models.Operation, error) {
+	var order string = "DESC"
+	if undone {
+		order = "ASC"
+	}
+
+	results, err := db.Conn.QueryContext(db.Context, `
+		SELECT
+			j.uid, j.command, j.action, j.journal_datetime, j.undone, je.entry_uid, je.field, je.old_value, je.new_value
+		FROM journal j
+		LEFT JOIN journal_entry je ON je.journal_uid = j.uid
+		WHERE j.undone = ?
+		ORDER BY j.uid `+order+`, je.rowid;
+		`, undone)
+
+	if err != nil {
+		return nil, wrapError("Error trying to retrieve journal records", err)
+	}
+
+	defer results.Close()
+
+	operations := []models.Operation{}
+	for results.Next() {
+		var o models.Operation
+		var entryUid sql.NullInt64
+		var field, oldValue, newValue sql.NullString
+		err = results.Scan(&o.Uid, &o.Command, &o.Action, &o.OperationDatetime, &o.Undone, &entryUid, &field, &oldValue, &newValue)
+		if err != nil {
+			return nil, wrapError("Error trying to Scan journal results into data structure", err)
+		}
+
+		var last *models.Operation
+		operations, last = groupRow(operations, o.Uid, func(operation models.Operation) int64 { return operation.Uid }, func() models.Operation {
+			var operation models.Operation = models.NewOperation(o.Command, o.Action, o.OperationDatetime)
+			operation.Uid = o.Uid
+			operation.Undone = o.Undone
+			return operation
+		})
+
+		if field.Valid {
+			last.Changes = append(last.Changes, models.NewChange(entryUid.Int64, field.String, oldValue.String, newValue.String, o.OperationDatetime, o.Command))
+		} else if entryUid.Valid {
+			last.EntryUids = append(last.EntryUids, entryUid.Int64)
+		}
+	}
+
+	return operations, wrapError("Error trying to retrieve journal records", results.Err())
+}
+
+// Revert the most recent operation that has not been undone.
+func (db *Database) Undo() (models.Operation, error) {
+	return db.replay(false)
+}
+
+// Reapply the least recent operation that has been undone.
+func (db *Database) Redo() (models.Operation, error) {
+	return db.replay(true)
+}
+
+func (db *Database) replay(redo bool) (models.Operation, error) {
+	var action string = constants.UNDO
+	if redo {
+		action = constants.REDO
+	}
+
+	var op string = "Error trying to " + action
+
+	operations, err := db.GetOperations(redo)
+	if err != nil {
+		return models.Operation{}, err
+	}
+
+	if len(operations) == 0 {
+		return models.Operation{}, &Error{Op: op, Kind: ErrNotFound, Err: fmt.Errorf("nothing to %s", action)}
+	}
+
+	var operation models.Operation = operations[0]
+
+	tx, err := db.Conn.BeginTx(db.Context, nil)
+	if err != nil {
+		return models.Operation{}, wrapError("Error trying to begin transaction", err)
+	}
+
+	// Undoing an insert or redoing a delete moves the entries to the trash,
+	// undoing a delete or redoing an insert moves them back.
+	var count int64
+	switch {
+	case operation.Action == constants.ACTION_UPDATE:
+		err = db.applyChanges(tx, operation.Changes, redo)
+	case (operation.Action == constants.ACTION_INSERT) != redo:
+		count, err = db.trashUids(tx, 0, operation.EntryUids)
+	default:
+		count, err = db.restoreFromTrash(tx, operation.EntryUids)
+	}
+
+	if err != nil {
+		tx.Rollback()
+		return models.Operation{}, wrapError(op+" "+operation.Command, err)
+	}
+
+	if operation.Action != constants.ACTION_UPDATE && count != int64(len(operation.EntryUids)) {
+		tx.Rollback()
+		return models.Operation{}, &Error{Op: op + " " + operation.Command, Kind: ErrNotFound, Err: fmt.Errorf("only %d of the %d entries were found, they may have been restored from or emptied out of the trash", count, len(operation.EntryUids))}
+	}
+
+	_, err = tx.ExecContext(db.Context, "UPDATE journal SET undone = ? WHERE uid = ?;", !redo, operation.Uid)
+	if err != nil {
+		tx.Rollback()
+		return models.Operation{}, wrapError("Error trying to update journal", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return models.Operation{}, wrapError("Error committing transaction", err)
+	}
+
+	return operation, nil
+}
+
+// Set each changed field to its new value, or back to its old value, and
+// record that in the entry_history table.
+func (db *Database) applyChanges(tx *sql.Tx, changes []models.Change, redo bool) error {
+	var command string = constants.UNDO
+	if redo {
+		command = constants.REDO
+	}
+
+	var changedDatetime string = carbon.Now().SetTimezone(carbon.UTC).ToRfc3339String()
+	var history []models.Change
+	for _, c := range changes {
+		var from, to string = c.NewValue, c.OldValue
+		if redo {
+			from, to = c.OldValue, c.NewValue
+		}
+
+		var count int64
+		var err error
+		switch c.Field {
+		case constants.PROJECT, constants.NOTE:
+			// The field is one of the column names above, never user input.
+			count, err = rowsAffected(tx.ExecContext(db.Context, "UPDATE entry SET "+c.Field+" = ? WHERE uid = ?;", to, c.EntryUid))
+		case constants.ENTRY_DATETIME:
+			entryDatetime, entryOffset := toUtc(to)
+			count, err = rowsAffected(tx.ExecContext(db.Context, "UPDATE entry SET entry_datetime = ?, entry_offset = ? WHERE uid = ?;", entryDatetime, entryOffset, c.EntryUid))
+		default:
+			count, err = db.replaceProperty(tx, c.EntryUid, c.Field, to)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// The entry, or its property, no longer exists.
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+
+		history = append(history, models.NewChange(c.EntryUid, c.Field, from, to, changedDatetime, command))
+	}
+
+	return db.recordHistory(tx, history)
+}
+
+// Returns the number of rows the statement affected.
+func rowsAffected(result sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (db *Database) recordHistory(tx *sql.Tx, changes []models.Change) error {
+	for _, c := range changes {
+		_, err := tx.ExecContext(db.Context, "INSERT INTO entry_history (entry_uid, field, old_value, new_value, changed_datetime, command) VALUES (?, ?, ?, ?, ?, ?);", c.EntryUid, c.Field, c.OldValue, c.NewValue, c.ChangedDatetime, c.Command)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,654 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"timetracker/constants"
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+// MemoryStore is a Store that keeps its entries in memory.  Nothing is ever
+// written to disk, so everything is lost once the MemoryStore goes away.  It
+// mirrors the behavior of the SQLite Database, including comparing date/times
+// as strings.
+type MemoryStore struct {
+	mutex   sync.Mutex
+	entries []models.Entry
+	trash   []models.TrashedEntry
+	history []models.Change
+	journal []models.Operation
+	lastUid int64
+	uid     string
+}
+
+func NewMemoryStore() *MemoryStore {
+	var random []byte = make([]byte, 16)
+	rand.Read(random)
+	return &MemoryStore{entries: make([]models.Entry, 0), trash: make([]models.TrashedEntry, 0), uid: hex.EncodeToString(random)}
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
+
+// Make a copy of the Entry so callers can never modify what is stored.
+func copyEntry(e models.Entry) models.Entry {
+	var c models.Entry = models.NewEntry(e.Uid, e.Project, e.Note, e.EntryDatetime)
+	c.Properties = append(c.Properties, e.Properties...)
+	return c
+}
+
+// Get a copy of the entries sorted by date/time, in UTC, the same way "ORDER
+// BY entry_datetime" would.
+func (m *MemoryStore) sorted() []models.Entry {
+	var sorted []models.Entry = make([]models.Entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		sorted = append(sorted, copyEntry(e))
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool { return utc(sorted[i].EntryDatetime) < utc(sorted[j].EntryDatetime) })
+	return sorted
+}
+
+func (m *MemoryStore) between(start carbon.Carbon, end carbon.Carbon) []models.Entry {
+	var from string = utcOf(start)
+	var to string = utcOf(end)
+
+	var records []models.Entry = []models.Entry{}
+	for _, e := range m.sorted() {
+		if utc(e.EntryDatetime) >= from && utc(e.EntryDatetime) <= to {
+			records = append(records, e)
+		}
+	}
+
+	return records
+}
+
+func (m *MemoryStore) InsertNewEntry(entry models.Entry, command string) error {
+	_, err := m.InsertNewEntries([]models.Entry{entry}, command)
+	return err
+}
+
+func (m *MemoryStore) InsertNewEntries(entries []models.Entry, command string) (int64, error) {
+	return m.insertNewEntries(entries, command, false)
+}
+
+func (m *MemoryStore) ImportEntries(entries []models.Entry, command string) (int64, error) {
+	return m.insertNewEntries(entries, command, true)
+}
+
+func (m *MemoryStore) insertNewEntries(entries []models.Entry, command string, keepUids bool) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// The kept uids go first, the same as Database.insertNewEntries.
+	if keepUids {
+		var kept []models.Entry
+		var others []models.Entry
+		for _, entry := range entries {
+			if entry.Uid > 0 && !m.uidInUse(entry.Uid) {
+				kept = append(kept, entry)
+			} else {
+				others = append(others, entry)
+			}
+		}
+
+		entries = append(kept, others...)
+	}
+
+	var uids []int64 = make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		var uid int64 = m.lastUid + 1
+		if keepUids && entry.Uid > 0 && !m.uidInUse(entry.Uid) {
+			uid = entry.Uid
+		}
+
+		if uid > m.lastUid {
+			m.lastUid = uid
+		}
+
+		var e models.Entry = models.NewEntry(uid, entry.Project, entry.Note, entry.EntryDatetime)
+		for _, p := range entry.Properties {
+			e.Properties = append(e.Properties, models.NewProperty(uid, p.Name, p.Value))
+		}
+
+		m.entries = append(m.entries, e)
+		uids = append(uids, e.Uid)
+	}
+
+	m.startOperation(command, constants.ACTION_INSERT).EntryUids = uids
+	return int64(len(entries)), nil
+}
+
+// Check if an entry, or an entry in the trash, has the uid.
+func (m *MemoryStore) uidInUse(uid int64) bool {
+	for _, e := range m.entries {
+		if e.Uid == uid {
+			return true
+		}
+	}
+
+	for _, t := range m.trash {
+		if t.Entry.Uid == uid {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *MemoryStore) GetEntriesBetween(start carbon.Carbon, end carbon.Carbon) ([]models.Entry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.between(start, end), nil
+}
+
+func (m *MemoryStore) GetEntry(uid int64) (models.Entry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, e := range m.entries {
+		if e.Uid == uid {
+			return withoutHelloProperties(copyEntry(e)), nil
+		}
+	}
+
+	return models.Entry{}, &Error{Op: "Error trying to retrieve Uid's Entry record", Kind: ErrNotFound, Err: ErrNotFound}
+}
+
+func (m *MemoryStore) GetFirstEntry() (models.Entry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.entries) == 0 {
+		return models.Entry{}, &Error{Op: "Error trying to retrieve first Uid", Kind: ErrNotFound, Err: ErrNotFound}
+	}
+
+	return withoutHelloProperties(m.sorted()[0]), nil
+}
+
+func (m *MemoryStore) GetLastEntry() (models.Entry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.entries) == 0 {
+		return models.Entry{}, &Error{Op: "Error trying to retrieve last Uid", Kind: ErrNotFound, Err: ErrNotFound}
+	}
+
+	var sorted []models.Entry = m.sorted()
+	return withoutHelloProperties(sorted[len(sorted)-1]), nil
+}
+
+// Like the Database, HELLO entries are returned without their properties.
+func withoutHelloProperties(e models.Entry) models.Entry {
+	if strings.EqualFold(e.Project, constants.HELLO) {
+		e.Properties = make([]models.Property, 0)
+	}
+
+	return e
+}
+
+func (m *MemoryStore) GetCountEntries() (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return int64(len(m.entries)), nil
+}
+
+// A MemoryStore only lasts as long as the process, so its uid is never stored
+// anywhere.
+func (m *MemoryStore) GetStoreUid() (string, error) {
+	return m.uid, nil
+}
+
+func (m *MemoryStore) UpdateEntry(entry models.Entry, command string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var changedDatetime string = carbon.Now().SetTimezone(carbon.UTC).ToRfc3339String()
+	var changes []models.Change
+	var record = func(field string, oldValue string, newValue string) {
+		changes = append(changes, models.NewChange(entry.Uid, field, oldValue, newValue, changedDatetime, command))
+	}
+
+	for i := range m.entries {
+		var e *models.Entry = &m.entries[i]
+		if e.Uid != entry.Uid {
+			continue
+		}
+
+		if entry.Project != constants.EMPTY && entry.Project != e.Project {
+			record(constants.PROJECT, e.Project, entry.Project)
+			e.Project = entry.Project
+		}
+
+		if len(entry.Note) > 0 && entry.Note != e.Note {
+			record(constants.NOTE, e.Note, entry.Note)
+			e.Note = entry.Note
+		}
+
+		if entry.EntryDatetime != constants.EMPTY && entry.EntryDatetime != e.EntryDatetime {
+			record(constants.ENTRY_DATETIME, e.EntryDatetime, entry.EntryDatetime)
+			e.EntryDatetime = entry.EntryDatetime
+		}
+
+		// Like the Database, only existing TASK and URL properties are updated.
+		var task = entry.GetTasksAsString()
+		if len(task) > 0 && e.GetTasksAsString() != task && hasProperty(*e, constants.TASK) {
+			record(constants.TASK, e.GetTasksAsString(), task)
+			setProperty(e, constants.TASK, task)
+		}
+
+		var url = entry.GetUrlAsString()
+		if len(url) > 0 && e.GetUrlAsString() != url && hasProperty(*e, constants.URL) {
+			record(constants.URL, e.GetUrlAsString(), url)
+			setProperty(e, constants.URL, url)
+		}
+
+		m.recordHistory(changes)
+
+		if len(changes) > 0 {
+			m.startOperation(command, constants.ACTION_UPDATE).Changes = changes
+		}
+
+		return nil
+	}
+
+	return &Error{Op: "Error trying to retrieve Uid's Entry record", Kind: ErrNotFound, Err: ErrNotFound}
+}
+
+func hasProperty(e models.Entry, name string) bool {
+	for _, p := range e.Properties {
+		if p.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Replace the properties with the specified name by one property for each of
+// the values joined together in value, the same way the Database does.
+func setProperty(e *models.Entry, name string, value string) {
+	var properties []models.Property
+	for _, p := range e.Properties {
+		if p.Name != name {
+			properties = append(properties, p)
+		}
+	}
+
+	for _, v := range splitPropertyValue(value) {
+		properties = append(properties, models.NewProperty(e.Uid, name, v))
+	}
+
+	e.Properties = properties
+}
+
+func (m *MemoryStore) GetHistory(entryUid int64) ([]models.Change, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var changes []models.Change = []models.Change{}
+	for _, c := range m.history {
+		if c.EntryUid == entryUid {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, nil
+}
+
+func (m *MemoryStore) GetHistorySince(since carbon.Carbon) ([]models.Change, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var from string = since.SetTimezone(carbon.UTC).ToRfc3339String()
+
+	var changes []models.Change = []models.Change{}
+	for _, c := range m.history {
+		if c.ChangedDatetime >= from {
+			changes = append(changes, c)
+		}
+	}
+
+	return changes, nil
+}
+
+func (m *MemoryStore) DeleteEntries(uids []int64) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var doomed map[int64]bool = make(map[int64]bool, len(uids))
+	for _, uid := range uids {
+		doomed[uid] = true
+	}
+
+	var trashed []int64 = m.moveToTrash(func(e models.Entry) bool { return doomed[e.Uid] })
+	m.startOperation(constants.DELETE, constants.ACTION_DELETE).EntryUids = trashed
+	return int64(len(trashed)), nil
+}
+
+func (m *MemoryStore) GetEntriesMatching(filter EntryFilter) ([]models.Entry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var records []models.Entry = []models.Entry{}
+	for _, e := range m.sorted() {
+		if filter.matches(e) {
+			records = append(records, e)
+		}
+	}
+
+	return records, nil
+}
+
+// There is no full-text index in memory, so every word, "quoted phrase", or
+// prefix* in the query must simply appear in the entry's project, tasks, note,
+// or url.
+func (m *MemoryStore) SearchEntries(query string, filter EntryFilter) ([]models.Entry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var terms []string = searchTerms(query)
+
+	var records []models.Entry = []models.Entry{}
+	for _, e := range m.sorted() {
+		if filter.matches(e) && searchMatches(terms, e) {
+			records = append(records, e)
+		}
+	}
+
+	return records, nil
+}
+
+// Split the query into its lowercase "quoted phrases" and words.
+func searchTerms(query string) []string {
+	var terms []string
+	for i, part := range strings.Split(strings.ToLower(query), "\"") {
+		if i%2 == 1 {
+			if phrase := strings.Join(searchWords(part), " "); phrase != constants.EMPTY {
+				terms = append(terms, phrase)
+			}
+		} else {
+			terms = append(terms, strings.Fields(part)...)
+		}
+	}
+
+	return terms
+}
+
+// Split the text into its lowercase words, keeping a trailing '*'.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '*'
+	})
+}
+
+func searchMatches(terms []string, e models.Entry) bool {
+	var text string = " " + strings.Join(searchWords(e.Project+" "+e.GetTasksAsString()+" "+e.Note+" "+e.GetUrlAsString()), " ") + " "
+	for _, term := range terms {
+		var found bool
+		if strings.HasSuffix(term, "*") {
+			found = strings.Contains(text, " "+strings.TrimSuffix(term, "*"))
+		} else {
+			found = strings.Contains(text, " "+term+" ")
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m *MemoryStore) NukeEntries(filter EntryFilter) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var uids []int64 = m.moveToTrash(filter.matches)
+	m.startOperation(constants.NUKE, constants.ACTION_DELETE).EntryUids = uids
+	return int64(len(uids)), nil
+}
+
+// Move the entries for which doomed returns true to the trash.  Returns the
+// uids of the entries moved.
+func (m *MemoryStore) moveToTrash(doomed func(e models.Entry) bool) []int64 {
+	var deletedDatetime string = carbon.Now().SetTimezone(carbon.UTC).ToRfc3339String()
+
+	var uids []int64 = make([]int64, 0)
+	var kept []models.Entry = make([]models.Entry, 0, len(m.entries))
+	for _, e := range m.entries {
+		if doomed(e) {
+			m.trash = append(m.trash, models.NewTrashedEntry(e, deletedDatetime))
+			uids = append(uids, e.Uid)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+
+	m.entries = kept
+	return uids
+}
+
+func (m *MemoryStore) GetTrashedEntries() ([]models.TrashedEntry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var trashed []models.TrashedEntry = make([]models.TrashedEntry, 0, len(m.trash))
+	for _, t := range m.trash {
+		trashed = append(trashed, models.NewTrashedEntry(copyEntry(t.Entry), t.DeletedDatetime))
+	}
+
+	sort.SliceStable(trashed, func(i, j int) bool { return utc(trashed[i].EntryDatetime) < utc(trashed[j].EntryDatetime) })
+	return trashed, nil
+}
+
+func (m *MemoryStore) RestoreFromTrash(uids []int64) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.restoreFromTrash(uids), nil
+}
+
+func (m *MemoryStore) restoreFromTrash(uids []int64) int64 {
+	var restore map[int64]bool = make(map[int64]bool, len(uids))
+	for _, uid := range uids {
+		restore[uid] = true
+	}
+
+	var count int64 = 0
+	var kept []models.TrashedEntry = make([]models.TrashedEntry, 0, len(m.trash))
+	for _, t := range m.trash {
+		if restore[t.Uid] {
+			m.entries = append(m.entries, t.Entry)
+			count += 1
+		} else {
+			kept = append(kept, t)
+		}
+	}
+
+	m.trash = kept
+	return count
+}
+
+func (m *MemoryStore) EmptyTrash(deletedBefore carbon.Carbon) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var before string = deletedBefore.SetTimezone(carbon.UTC).ToRfc3339String()
+
+	var count int64 = 0
+	var kept []models.TrashedEntry = make([]models.TrashedEntry, 0, len(m.trash))
+	for _, t := range m.trash {
+		if t.DeletedDatetime <= before {
+			count += 1
+		} else {
+			kept = append(kept, t)
+		}
+	}
+
+	m.trash = kept
+	return count, nil
+}
+
+func (m *MemoryStore) recordHistory(changes []models.Change) {
+	for _, c := range changes {
+		c.Uid = int64(len(m.history) + 1)
+		m.history = append(m.history, c)
+	}
+}
+
+// Like the Database, starting a new operation discards the operations that
+// could have been redone.
+func (m *MemoryStore) startOperation(command string, action string) *models.Operation {
+	var kept []models.Operation = make([]models.Operation, 0, len(m.journal)+1)
+	var lastUid int64 = 0
+	for _, o := range m.journal {
+		lastUid = o.Uid
+		if !o.Undone {
+			kept = append(kept, o)
+		}
+	}
+
+	var operation models.Operation = models.NewOperation(command, action, carbon.Now().SetTimezone(carbon.UTC).ToRfc3339String())
+	operation.Uid = lastUid + 1
+	m.journal = append(kept, operation)
+	return &m.journal[len(m.journal)-1]
+}
+
+func (m *MemoryStore) GetOperations(undone bool) ([]models.Operation, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.operations(undone), nil
+}
+
+func (m *MemoryStore) operations(undone bool) []models.Operation {
+	var operations []models.Operation = []models.Operation{}
+	for _, o := range m.journal {
+		if o.Undone == undone {
+			operations = append(operations, o)
+		}
+	}
+
+	// Most recent first for undo, least recent first for redo.
+	if !undone {
+		sort.SliceStable(operations, func(i, j int) bool { return operations[i].Uid > operations[j].Uid })
+	}
+
+	return operations
+}
+
+func (m *MemoryStore) Undo() (models.Operation, error) {
+	return m.replay(false)
+}
+
+func (m *MemoryStore) Redo() (models.Operation, error) {
+	return m.replay(true)
+}
+
+func (m *MemoryStore) replay(redo bool) (models.Operation, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var action string = constants.UNDO
+	if redo {
+		action = constants.REDO
+	}
+
+	var op string = "Error trying to " + action
+
+	var operations []models.Operation = m.operations(redo)
+	if len(operations) == 0 {
+		return models.Operation{}, &Error{Op: op, Kind: ErrNotFound, Err: fmt.Errorf("nothing to %s", action)}
+	}
+
+	var operation models.Operation = operations[0]
+
+	var count int64
+	switch {
+	case operation.Action == constants.ACTION_UPDATE:
+		err := m.applyChanges(operation.Changes, redo)
+		if err != nil {
+			return models.Operation{}, &Error{Op: op + " " + operation.Command, Kind: ErrNotFound, Err: err}
+		}
+	case (operation.Action == constants.ACTION_INSERT) != redo:
+		var trash map[int64]bool = make(map[int64]bool, len(operation.EntryUids))
+		for _, uid := range operation.EntryUids {
+			trash[uid] = true
+		}
+
+		count = int64(len(m.moveToTrash(func(e models.Entry) bool { return trash[e.Uid] })))
+	default:
+		count = m.restoreFromTrash(operation.EntryUids)
+	}
+
+	if operation.Action != constants.ACTION_UPDATE && count != int64(len(operation.EntryUids)) {
+		return models.Operation{}, &Error{Op: op + " " + operation.Command, Kind: ErrNotFound, Err: fmt.Errorf("only %d of the %d entries were found, they may have been restored from or emptied out of the trash", count, len(operation.EntryUids))}
+	}
+
+	for i := range m.journal {
+		if m.journal[i].Uid == operation.Uid {
+			m.journal[i].Undone = !redo
+		}
+	}
+
+	return operation, nil
+}
+
+// Database.applyChanges for the MemoryStore, recording the changes in its
+// history.
+func (m *MemoryStore) applyChanges(changes []models.Change, redo bool) error {
+	var command string = constants.UNDO
+	if redo {
+		command = constants.REDO
+	}
+
+	var changedDatetime string = carbon.Now().SetTimezone(carbon.UTC).ToRfc3339String()
+	var history []models.Change
+	for _, c := range changes {
+		var from, to string = c.NewValue, c.OldValue
+		if redo {
+			from, to = c.OldValue, c.NewValue
+		}
+
+		var found bool = false
+		for i := range m.entries {
+			var e *models.Entry = &m.entries[i]
+			if e.Uid != c.EntryUid {
+				continue
+			}
+
+			found = true
+			switch c.Field {
+			case constants.PROJECT:
+				e.Project = to
+			case constants.NOTE:
+				e.Note = to
+			case constants.ENTRY_DATETIME:
+				e.EntryDatetime = to
+			default:
+				setProperty(e, c.Field, to)
+			}
+		}
+
+		if !found {
+			return ErrNotFound
+		}
+
+		history = append(history, models.NewChange(c.EntryUid, c.Field, from, to, changedDatetime, command))
+	}
+
+	m.recordHistory(history)
+	return nil
+}
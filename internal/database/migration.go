@@ -0,0 +1,245 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+type Migration struct {
+	Version     int
+	Description string
+	Statements  []string
+}
+
+type AppliedMigration struct {
+	Version         int
+	Description     string
+	AppliedDatetime string
+}
+
+// The ordered list of schema migrations.  Each migration is applied, in its
+// own transaction, exactly once.  NEVER modify or reorder a migration that has
+// already been released, simply append a new one to the end of the list.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Create entry and property tables",
+		Statements: []string{
+			"CREATE TABLE IF NOT EXISTS entry (uid INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, project TEXT(128) NOT NULL, note TEXT(128), entry_datetime TEXT NOT NULL);",
+			"CREATE TABLE IF NOT EXISTS property (entry_uid INTEGER NOT NULL, name TEXT(128) NOT NULL, value TEXT(128) NOT NULL, CONSTRAINT property_FK FOREIGN KEY (entry_uid) REFERENCES entry(uid) ON DELETE CASCADE);",
+		},
+	},
+	{
+		Version:     2,
+		Description: "Index entry date/times and property entry uids",
+		Statements: []string{
+			"CREATE INDEX IF NOT EXISTS entry_entry_datetime_IDX ON entry (entry_datetime);",
+			"CREATE INDEX IF NOT EXISTS property_entry_uid_IDX ON property (entry_uid);",
+		},
+	},
+	{
+		Version:     3,
+		Description: "Create trash_entry and trash_property tables",
+		Statements: []string{
+			"CREATE TABLE IF NOT EXISTS trash_entry (uid INTEGER NOT NULL PRIMARY KEY, project TEXT(128) NOT NULL, note TEXT(128), entry_datetime TEXT NOT NULL, deleted_datetime TEXT NOT NULL);",
+			"CREATE TABLE IF NOT EXISTS trash_property (entry_uid INTEGER NOT NULL, name TEXT(128) NOT NULL, value TEXT(128) NOT NULL, CONSTRAINT trash_property_FK FOREIGN KEY (entry_uid) REFERENCES trash_entry(uid) ON DELETE CASCADE);",
+			"CREATE INDEX IF NOT EXISTS trash_property_entry_uid_IDX ON trash_property (entry_uid);",
+		},
+	},
+	{
+		Version:     4,
+		Description: "Create append-only entry_history table",
+		Statements: []string{
+			// There is deliberately no foreign key to entry, the history must
+			// outlive the entries it describes.
+			"CREATE TABLE IF NOT EXISTS entry_history (uid INTEGER NOT NULL PRIMARY KEY, entry_uid INTEGER NOT NULL, field TEXT(128) NOT NULL, old_value TEXT, new_value TEXT, changed_datetime TEXT NOT NULL, command TEXT(128) NOT NULL);",
+			"CREATE INDEX IF NOT EXISTS entry_history_entry_uid_IDX ON entry_history (entry_uid);",
+			"CREATE INDEX IF NOT EXISTS entry_history_changed_datetime_IDX ON entry_history (changed_datetime);",
+			"CREATE TRIGGER IF NOT EXISTS entry_history_no_update BEFORE UPDATE ON entry_history BEGIN SELECT RAISE(ABORT, 'entry_history is append-only'); END;",
+			"CREATE TRIGGER IF NOT EXISTS entry_history_no_delete BEFORE DELETE ON entry_history BEGIN SELECT RAISE(ABORT, 'entry_history is append-only'); END;",
+		},
+	},
+	{
+		Version:     5,
+		Description: "Create journal and journal_entry tables",
+		Statements: []string{
+			"CREATE TABLE IF NOT EXISTS journal (uid INTEGER NOT NULL PRIMARY KEY, command TEXT(128) NOT NULL, action TEXT(128) NOT NULL, journal_datetime TEXT NOT NULL, undone INTEGER NOT NULL DEFAULT 0);",
+			"CREATE TABLE IF NOT EXISTS journal_entry (journal_uid INTEGER NOT NULL, entry_uid INTEGER NOT NULL, field TEXT(128), old_value TEXT, new_value TEXT, CONSTRAINT journal_entry_FK FOREIGN KEY (journal_uid) REFERENCES journal(uid) ON DELETE CASCADE);",
+			"CREATE INDEX IF NOT EXISTS journal_entry_journal_uid_IDX ON journal_entry (journal_uid);",
+		},
+	},
+	{
+		Version:     6,
+		Description: "Store entry date/times in UTC along with their original offset",
+		Statements: []string{
+			"ALTER TABLE entry ADD COLUMN entry_offset TEXT;",
+			"ALTER TABLE trash_entry ADD COLUMN entry_offset TEXT;",
+			// Only date/times ending in an offset or 'Z' can be converted,
+			// anything else is left alone.  SQLite's strftime() converts the
+			// date/time to UTC.
+			"UPDATE entry SET entry_offset = CASE WHEN entry_datetime GLOB '*Z' THEN '+00:00' ELSE substr(entry_datetime, -6) END, entry_datetime = strftime('%Y-%m-%dT%H:%M:%SZ', entry_datetime) WHERE (entry_datetime GLOB '*Z' OR entry_datetime GLOB '*[+-][0-9][0-9]:[0-9][0-9]') AND strftime('%Y-%m-%dT%H:%M:%SZ', entry_datetime) IS NOT NULL;",
+			"UPDATE trash_entry SET entry_offset = CASE WHEN entry_datetime GLOB '*Z' THEN '+00:00' ELSE substr(entry_datetime, -6) END, entry_datetime = strftime('%Y-%m-%dT%H:%M:%SZ', entry_datetime) WHERE (entry_datetime GLOB '*Z' OR entry_datetime GLOB '*[+-][0-9][0-9]:[0-9][0-9]') AND strftime('%Y-%m-%dT%H:%M:%SZ', entry_datetime) IS NOT NULL;",
+		},
+	},
+	{
+		Version:     7,
+		Description: "Create entry_fts full-text search index",
+		Statements: []string{
+			"CREATE VIRTUAL TABLE IF NOT EXISTS entry_fts USING fts4(project, tasks, note, url);",
+			"INSERT INTO entry_fts (rowid, project, tasks, note, url) " + ftsSelect + " FROM entry e;",
+			"CREATE TRIGGER IF NOT EXISTS entry_fts_insert AFTER INSERT ON entry BEGIN " + ftsRefresh("NEW.uid") + " END;",
+			"CREATE TRIGGER IF NOT EXISTS entry_fts_update AFTER UPDATE ON entry BEGIN " + ftsRefresh("NEW.uid") + " END;",
+			"CREATE TRIGGER IF NOT EXISTS entry_fts_delete AFTER DELETE ON entry BEGIN DELETE FROM entry_fts WHERE rowid = OLD.uid; END;",
+			"CREATE TRIGGER IF NOT EXISTS property_fts_insert AFTER INSERT ON property BEGIN " + ftsRefresh("NEW.entry_uid") + " END;",
+			"CREATE TRIGGER IF NOT EXISTS property_fts_update AFTER UPDATE ON property BEGIN " + ftsRefresh("NEW.entry_uid") + " END;",
+			"CREATE TRIGGER IF NOT EXISTS property_fts_delete AFTER DELETE ON property BEGIN " + ftsRefresh("OLD.entry_uid") + " END;",
+		},
+	},
+	{
+		// Entry uids are only unique within a database, so anything exported
+		// that must be unique everywhere, e.g. iCalendar event uids, includes
+		// this random uid as well.
+		Version:     8,
+		Description: "Create store table with a random uid for the database",
+		Statements: []string{
+			"CREATE TABLE IF NOT EXISTS store (uid TEXT NOT NULL);",
+			"INSERT INTO store (uid) SELECT lower(hex(randomblob(16))) WHERE NOT EXISTS (SELECT 1 FROM store);",
+		},
+	},
+}
+
+// Select an entry's row for the entry_fts table, with its tasks and urls
+// joined together.
+const ftsSelect string = "SELECT e.uid, e.project, (SELECT group_concat(value, ' ') FROM property WHERE entry_uid = e.uid AND name = 'task'), e.note, (SELECT group_concat(value, ' ') FROM property WHERE entry_uid = e.uid AND name = 'url')"
+
+// The trigger statements that replace the entry_fts row of the entry with the
+// specified uid, e.g. "NEW.uid", so the index stays in sync with the entry and
+// property tables.
+func ftsRefresh(uid string) string {
+	return "DELETE FROM entry_fts WHERE rowid = " + uid + "; INSERT INTO entry_fts (rowid, project, tasks, note, url) " + ftsSelect + " FROM entry e WHERE e.uid = " + uid + ";"
+}
+
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+func (db *Database) hasSchemaVersionTable() (bool, error) {
+	var count int64
+	err := db.Conn.QueryRowContext(db.Context, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_version';").Scan(&count)
+	if err != nil {
+		return false, wrapError("Error trying to look up the schema_version table", err)
+	}
+
+	return count > 0, nil
+}
+
+func (db *Database) GetSchemaVersion() (int, error) {
+	// A database without a schema_version table predates migrations, so
+	// report it as version 0.
+	found, err := db.hasSchemaVersionTable()
+	if err != nil || !found {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	err = db.Conn.QueryRowContext(db.Context, "SELECT MAX(version) FROM schema_version;").Scan(&version)
+	if err != nil {
+		return 0, wrapError("Error trying to retrieve schema version", err)
+	}
+
+	return int(version.Int64), nil
+}
+
+func (db *Database) GetAppliedMigrations() ([]AppliedMigration, error) {
+	records := []AppliedMigration{}
+	found, err := db.hasSchemaVersionTable()
+	if err != nil || !found {
+		return records, err
+	}
+
+	results, err := db.Conn.QueryContext(db.Context, "SELECT version, description, applied_datetime FROM schema_version ORDER BY version;")
+	if err != nil {
+		return nil, wrapError("Error trying to retrieve applied migrations", err)
+	}
+
+	defer results.Close()
+
+	for results.Next() {
+		var applied AppliedMigration
+		err = results.Scan(&applied.Version, &applied.Description, &applied.AppliedDatetime)
+		if err != nil {
+			return nil, wrapError("Error trying to Scan applied migrations into data structure", err)
+		}
+
+		records = append(records, applied)
+	}
+
+	return records, wrapError("Error trying to retrieve applied migrations", results.Err())
+}
+
+func (db *Database) GetPendingMigrations() ([]Migration, error) {
+	version, err := db.GetSchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Migration{}
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+func (db *Database) Migrate() ([]Migration, error) {
+	_, err := db.Conn.ExecContext(db.Context, "CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL PRIMARY KEY, description TEXT NOT NULL, applied_datetime TEXT NOT NULL);")
+	if err != nil {
+		return nil, wrapError("Error trying to create the schema_version table", err)
+	}
+
+	pending, err := db.GetPendingMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range pending {
+		err = db.applyMigration(m)
+		if err != nil {
+			return pending[:i], err
+		}
+	}
+
+	return pending, nil
+}
+
+func (db *Database) applyMigration(m Migration) error {
+	tx, err := db.Conn.BeginTx(db.Context, nil)
+	if err != nil {
+		return wrapError("Error trying to begin transaction", err)
+	}
+
+	for _, statement := range m.Statements {
+		_, err = tx.ExecContext(db.Context, statement)
+		if err != nil {
+			tx.Rollback()
+			return wrapError(fmt.Sprintf("Error applying migration %d (%s)", m.Version, m.Description), err)
+		}
+	}
+
+	_, err = tx.ExecContext(db.Context, "INSERT INTO schema_version (version, description, applied_datetime) VALUES (?, ?, ?);", m.Version, m.Description, carbon.Now().ToRfc3339String())
+	if err != nil {
+		tx.Rollback()
+		return wrapError(fmt.Sprintf("Error recording migration %d", m.Version), err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return wrapError(fmt.Sprintf("Error committing migration %d", m.Version), err)
+	}
+
+	return nil
+}
@@ -1,8 +1,17 @@
 package database
 
-import "database/sql"
+import (
+	"database/sql"
+	"strings"
+)
 
 type Property struct {
 	Name  sql.NullString
 	Value sql.NullString
 }
+
+// Split a value made of several properties with the same name, joined together
+// the way they are shown, e.g. "design, review", back into their values.
+func splitPropertyValue(value string) []string {
+	return strings.Split(value, ", ")
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+// Store is the set of operations the commands perform against the entries,
+// regardless of where those entries are kept.  Database is the SQLite
+// implementation and MemoryStore is the in-memory implementation.
+type Store interface {
+	// Insert a new Entry along with its properties, journaling it as performed
+	// by the specified command.
+	InsertNewEntry(entry models.Entry, command string) error
+
+	// Insert the new Entries along with their properties, journaling them as
+	// a single operation performed by the specified command.
+	InsertNewEntries(entries []models.Entry, command string) (int64, error)
+
+	// Insert the Entries the same way InsertNewEntries does, except each
+	// Entry keeps its uid unless the uid is unknown or already in use.
+	ImportEntries(entries []models.Entry, command string) (int64, error)
+
+	// Get the Entries, with their properties, between start and end ordered
+	// by date/time.
+	GetEntriesBetween(start carbon.Carbon, end carbon.Carbon) ([]models.Entry, error)
+
+	// Get the Entry, with its properties, with the specified uid.
+	GetEntry(uid int64) (models.Entry, error)
+
+	GetFirstEntry() (models.Entry, error)
+	GetLastEntry() (models.Entry, error)
+	GetCountEntries() (int64, error)
+
+	// Get the random uid that tells this store apart from every other one,
+	// since entry uids are only unique within a store.
+	GetStoreUid() (string, error)
+
+	// Update the non-empty fields of the Entry with the matching uid,
+	// recording each changed field, along with the command that changed it,
+	// in the Entry's history.
+	UpdateEntry(entry models.Entry, command string) error
+
+	// Get the recorded changes to the Entry with the specified uid, oldest
+	// first.
+	GetHistory(entryUid int64) ([]models.Change, error)
+
+	// Get the recorded changes to any Entry made on or after since, oldest
+	// first.
+	GetHistorySince(since carbon.Carbon) ([]models.Change, error)
+
+	// Move the Entries, and their properties, with the specified uids to the
+	// trash.
+	DeleteEntries(uids []int64) (int64, error)
+
+	// Get the Entries, with their properties, selected by the filter ordered
+	// by date/time.
+	GetEntriesMatching(filter EntryFilter) ([]models.Entry, error)
+
+	// Move the Entries, and their properties, selected by the filter to the
+	// trash.
+	NukeEntries(filter EntryFilter) (int64, error)
+
+	// Get the Entries, with their properties, whose project, tasks, note, or
+	// url match the full-text search query and that are selected by the
+	// filter, ordered by date/time.
+	SearchEntries(query string, filter EntryFilter) ([]models.Entry, error)
+
+	// Get the Entries, with their properties, in the trash ordered by
+	// date/time.
+	GetTrashedEntries() ([]models.TrashedEntry, error)
+
+	// Move the Entries with the specified uids out of the trash.
+	RestoreFromTrash(uids []int64) (int64, error)
+
+	// Permanently delete the Entries moved to the trash on or before the
+	// specified date/time.
+	EmptyTrash(deletedBefore carbon.Carbon) (int64, error)
+
+	// Get the journaled operations that can be undone, most recent first, or
+	// that can be redone, least recent first.
+	GetOperations(undone bool) ([]models.Operation, error)
+
+	// Revert the most recent journaled operation that has not been undone.
+	Undo() (models.Operation, error)
+
+	// Reapply the least recent journaled operation that has been undone.
+	Redo() (models.Operation, error)
+
+	Close() error
+}
+
+// Make sure both implementations satisfy the Store interface.
+var _ Store = (*Database)(nil)
+var _ Store = (*MemoryStore)(nil)
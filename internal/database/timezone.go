@@ -0,0 +1,60 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+// Entry date/times are stored in UTC so they sort and compare correctly no
+// matter which time zone, or which side of a daylight saving time change, they
+// were recorded in.  The offset each was recorded with is kept in the
+// entry_offset column so it can be given back exactly as it was recorded.
+
+// The layout of the stored UTC date/times.
+const utcLayout string = "2006-01-02T15:04:05Z"
+
+// Split an ISO8601 date/time into its UTC date/time and the offset it was
+// recorded with, e.g. "2024-03-10T09:30:00-04:00" into "2024-03-10T13:30:00Z"
+// and "-04:00".  A date/time that cannot be parsed is returned as is, without
+// an offset.
+func toUtc(datetime string) (string, sql.NullString) {
+	t, err := time.Parse(time.RFC3339, datetime)
+	if err != nil {
+		return datetime, sql.NullString{}
+	}
+
+	return t.UTC().Format(utcLayout), sql.NullString{String: t.Format("-07:00"), Valid: true}
+}
+
+// The UTC date/time to compare stored date/times against.
+func utc(datetime string) string {
+	utc, _ := toUtc(datetime)
+	return utc
+}
+
+func utcOf(c carbon.Carbon) string {
+	return c.StdTime().UTC().Format(utcLayout)
+}
+
+// Join a stored UTC date/time and the offset it was recorded with back into
+// the ISO8601 date/time it was recorded as.
+func fromUtc(datetime string, offset sql.NullString) string {
+	if !offset.Valid {
+		return datetime
+	}
+
+	t, err := time.Parse(time.RFC3339, datetime)
+	if err != nil {
+		return datetime
+	}
+
+	o, err := time.Parse("-07:00", offset.String)
+	if err != nil {
+		return datetime
+	}
+
+	_, seconds := o.Zone()
+	return t.In(time.FixedZone(offset.String, seconds)).Format(time.RFC3339)
+}
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+
+	"timetracker/internal/models"
+
+	"github.com/golang-module/carbon/v2"
+)
+
+// The maximum number of uids bound to a single "IN" clause.
+const maxUidsPerStatement int = 500
+
+// Build "?, ?, ..., ?" with count placeholders.
+func placeholders(count int) string {
+	if count <= 0 {
+		return ""
+	}
+
+	return "?" + strings.Repeat(", ?", count-1)
+}
+
+// Split the uids into chunks small enough to bind to a single statement.
+func chunkUids(uids []int64) [][]any {
+	var chunks [][]any
+	for len(uids) > 0 {
+		var size int = min(len(uids), maxUidsPerStatement)
+
+		var chunk []any = make([]any, size)
+		for i, uid := range uids[:size] {
+			chunk[i] = uid
+		}
+
+		chunks = append(chunks, chunk)
+		uids = uids[size:]
+	}
+
+	return chunks
+}
+
+// Move the entry records matching the where clause, along with their property
+// records, to the trash.  Unless journalUid is 0, the moved entries are
+// journaled under it.  Returns the number of entries moved.
+func (db *Database) moveToTrash(tx *sql.Tx, journalUid int64, where string, args ...any) (int64, error) {
+	var deletedDatetime string = carbon.Now().SetTimezone(carbon.UTC).ToRfc3339String()
+
+	if journalUid != 0 {
+		_, err := tx.ExecContext(db.Context, "INSERT INTO journal_entry (journal_uid, entry_uid) SELECT ?, uid FROM entry WHERE "+where+" ORDER BY uid;", append([]any{journalUid}, args...)...)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	_, err := tx.ExecContext(db.Context, "INSERT INTO trash_entry (uid, project, note, entry_datetime, entry_offset, deleted_datetime) SELECT uid, project, note, entry_datetime, entry_offset, ? FROM entry WHERE "+where+";", append([]any{deletedDatetime}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(db.Context, "INSERT INTO trash_property (entry_uid, name, value) SELECT entry_uid, name, value FROM property WHERE entry_uid IN (SELECT uid FROM entry WHERE "+where+") ORDER BY rowid;", args...)
+	if err != nil {
+		return 0, err
+	}
+
+	// The property records are deleted via 'ON DELETE CASCADE'.
+	result, err := tx.ExecContext(db.Context, "DELETE FROM entry WHERE "+where+";", args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Move the entries with the specified uids to the trash.  Returns the number of
+// entries moved.
+func (db *Database) trashUids(tx *sql.Tx, journalUid int64, uids []int64) (int64, error) {
+	var count int64 = 0
+	for _, chunk := range chunkUids(uids) {
+		moved, err := db.moveToTrash(tx, journalUid, "uid IN ("+placeholders(len(chunk))+")", chunk...)
+		if err != nil {
+			return 0, err
+		}
+
+		count += moved
+	}
+
+	return count, nil
+}
+
+func (db *Database) GetTrashedEntries() ([]models.TrashedEntry, error) {
+	results, err := db.Conn.QueryContext(db.Context, `
+		SELECT
+			t.uid, t.project, t.note, t.entry_datetime, t.entry_offset, t.deleted_datetime, p.name, p.value
+		FROM trash_entry t
+		LEFT JOIN trash_property p ON p.entry_uid = t.uid
+		ORDER BY t.entry_datetime, t.uid, p.rowid;
+		`)
+
+	if err != nil {
+		return nil, wrapError("Error trying to retrieve trashed Entry records", err)
+	}
+
+	defer results.Close()
+
+	trashed := []models.TrashedEntry{}
+	for results.Next() {
+		var entry Entry
+		var deletedDatetime string
+		var property Property
+		err = results.Scan(&entry.Uid, &entry.Project, &entry.Note, &entry.EntryDatetime, &entry.EntryOffset, &deletedDatetime, &property.Name, &property.Value)
+		if err != nil {
+			return nil, wrapError("Error trying to Scan trashed Entries results into data structure", err)
+		}
+
+		var last *models.TrashedEntry
+		trashed, last = groupRow(trashed, entry.Uid, func(t models.TrashedEntry) int64 { return t.Uid }, func() models.TrashedEntry {
+			return models.NewTrashedEntry(models.NewEntry(entry.Uid, entry.Project, entry.Note.String, entry.recordedDatetime()), deletedDatetime)
+		})
+
+		if property.Name.Valid {
+			last.AddEntryProperty(property.Name.String, property.Value.String)
+		}
+	}
+
+	return trashed, wrapError("Error trying to retrieve trashed Entry records", results.Err())
+}
+
+// Move the Entries with the specified uids, along with their properties, out
+// of the trash and back into the database.  Returns the number restored.
+func (db *Database) RestoreFromTrash(uids []int64) (int64, error) {
+	tx, err := db.Conn.BeginTx(db.Context, nil)
+	if err != nil {
+		return 0, wrapError("Error trying to begin transaction", err)
+	}
+
+	count, err := db.restoreFromTrash(tx, uids)
+	if err != nil {
+		tx.Rollback()
+		return 0, wrapError("Error trying to restore entries", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, wrapError("Error committing transaction", err)
+	}
+
+	return count, nil
+}
+
+func (db *Database) restoreFromTrash(tx *sql.Tx, uids []int64) (int64, error) {
+	var count int64 = 0
+	for _, chunk := range chunkUids(uids) {
+		var in string = placeholders(len(chunk))
+
+		_, err := tx.ExecContext(db.Context, "INSERT INTO entry (uid, project, note, entry_datetime, entry_offset) SELECT uid, project, note, entry_datetime, entry_offset FROM trash_entry WHERE uid IN ("+in+");", chunk...)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.ExecContext(db.Context, "INSERT INTO property (entry_uid, name, value) SELECT entry_uid, name, value FROM trash_property WHERE entry_uid IN ("+in+") ORDER BY rowid;", chunk...)
+		if err != nil {
+			return 0, err
+		}
+
+		// The trash_property records are deleted via 'ON DELETE CASCADE'.
+		result, err := tx.ExecContext(db.Context, "DELETE FROM trash_entry WHERE uid IN ("+in+");", chunk...)
+		if err != nil {
+			return 0, err
+		}
+
+		restored, _ := result.RowsAffected()
+		count += restored
+	}
+
+	return count, nil
+}
+
+// Permanently delete everything that was moved to the trash on or before the
+// specified date/time.  Returns the number of entries deleted.
+func (db *Database) EmptyTrash(deletedBefore carbon.Carbon) (int64, error) {
+	result, err := db.Conn.ExecContext(db.Context, "DELETE FROM trash_entry WHERE deleted_datetime <= ?;", deletedBefore.SetTimezone(carbon.UTC).ToRfc3339String())
+	if err != nil {
+		return 0, wrapError("Error trying to empty the trash", err)
+	}
+
+	return result.RowsAffected()
+}
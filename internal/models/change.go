@@ -0,0 +1,17 @@
+package models
+
+// A Change records a single field of an Entry being changed by a command.
+type Change struct {
+	Uid             int64
+	EntryUid        int64
+	Field           string
+	OldValue        string
+	NewValue        string
+	ChangedDatetime string
+	Command         string
+}
+
+func NewChange(entryUid int64, field string, oldValue string, newValue string, changedDatetime string, command string) Change {
+	var c Change = Change{0, entryUid, field, oldValue, newValue, changedDatetime, command}
+	return c
+}
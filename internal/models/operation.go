@@ -0,0 +1,22 @@
+package models
+
+// An Operation is a journaled insert, update, or delete of one or more Entries
+// that can be undone and redone.
+type Operation struct {
+	Uid               int64
+	Command           string
+	Action            string
+	OperationDatetime string
+	Undone            bool
+
+	// The uids of the inserted or deleted Entries.
+	EntryUids []int64
+
+	// The changed fields of the updated Entry.
+	Changes []Change
+}
+
+func NewOperation(command string, action string, operationDatetime string) Operation {
+	var o Operation = Operation{0, command, action, operationDatetime, false, make([]int64, 0), make([]Change, 0)}
+	return o
+}
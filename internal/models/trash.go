@@ -0,0 +1,11 @@
+package models
+
+type TrashedEntry struct {
+	Entry
+	DeletedDatetime string
+}
+
+func NewTrashedEntry(entry Entry, deletedDatetime string) TrashedEntry {
+	var t TrashedEntry = TrashedEntry{entry, deletedDatetime}
+	return t
+}
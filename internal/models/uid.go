@@ -1,12 +1,13 @@
 package models
 
 type UID struct {
-	Uid           int64
-	EntryDatetime string
-	Duration      int64
+	Uid             int64
+	EntryDatetime   string
+	Duration        int64
+	RoundedDuration int64
 }
 
-func NewUID(uid int64, entryDatetime string, duration int64) UID {
-	var u UID = UID{uid, entryDatetime, duration}
+func NewUID(uid int64, entryDatetime string, duration int64, roundedDuration int64) UID {
+	var u UID = UID{uid, entryDatetime, duration, roundedDuration}
 	return u
 }